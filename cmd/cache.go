@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/aerokube/cm/selenoid"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	cacheCmd.AddCommand(cacheListCmd)
+	cacheCmd.AddCommand(cacheCleanCmd)
+	for _, c := range []*cobra.Command{
+		cacheListCmd,
+		cacheCleanCmd,
+	} {
+		c.Flags().BoolVarP(&quiet, "quiet", "q", false, "suppress output")
+		c.Flags().StringVarP(&cacheDir, "cache-dir", "", selenoid.GetCacheDir(), "directory with cached drivers and binaries")
+	}
+}
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage cache of downloaded drivers and binaries",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Usage()
+	},
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Shows cached files",
+	Run: func(cmd *cobra.Command, args []string) {
+		cacheImpl(func(cache *selenoid.Cache) error {
+			return cache.List(os.Stdout)
+		}, "Failed to list cached files: %v\n")
+	},
+}
+
+var cacheCleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Removes all cached files",
+	Run: func(cmd *cobra.Command, args []string) {
+		cacheImpl(func(cache *selenoid.Cache) error {
+			return cache.Clean()
+		}, "Failed to clean cache: %v\n")
+	},
+}
+
+func cacheImpl(cacheAction func(*selenoid.Cache) error, errorMessage string) {
+	cache := selenoid.NewCache(cacheDir, quiet)
+	err := cacheAction(cache)
+	if err != nil {
+		cache.Errorf(errorMessage, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
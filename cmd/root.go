@@ -1,14 +1,27 @@
 package cmd
 
 import (
-	"github.com/spf13/cobra"
+	"context"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
 )
 
 var (
-	quiet    bool
-	registry string
-	rootCmd  = &cobra.Command{
+	quiet                 bool
+	registry              string
+	browsersRegistry      string
+	registryUsername      string
+	registryPassword      string
+	registryPasswordStdin bool
+	browsersRegUsername   string
+	browsersRegPassword   string
+	browsersRegPassStdin  bool
+	timeout               time.Duration
+	rootCmd               = &cobra.Command{
 		Use:   "cm",
 		Short: "cm is a configuration management tool for Aerokube products",
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -18,9 +31,11 @@ var (
 )
 
 func init() {
+	rootCmd.PersistentFlags().DurationVarP(&timeout, "timeout", "", 0, "cancel command if it does not complete in specified time (0 to wait forever)")
 	rootCmd.AddCommand(selenoidCmd)
 	rootCmd.AddCommand(selenoidUICmd)
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(cacheCmd)
 }
 
 func Execute() {
@@ -28,3 +43,21 @@ func Execute() {
 		os.Exit(1)
 	}
 }
+
+// commandContext returns context cancelled on SIGINT, SIGTERM or when --timeout expires.
+// Only the first signal cancels the context, the next one terminates cm immediately.
+func commandContext() (context.Context, context.CancelFunc) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+	if timeout <= 0 {
+		return ctx, stop
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return ctx, func() {
+		cancel()
+		stop()
+	}
+}
@@ -1,15 +1,20 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/aerokube/cm/selenoid"
 	"github.com/spf13/cobra"
 )
 
+const githubTokenEnv = "GITHUB_TOKEN"
+
 var (
 	lastVersions    int
 	tmpfs           int
@@ -20,6 +25,7 @@ var (
 	browsers        string
 	useDrivers      bool
 	browsersJson    string
+	imagesCatalog   string
 	driversInfoUrl  string
 	configDir       string
 	uiConfigDir     string
@@ -35,6 +41,28 @@ var (
 	uiPort          uint16
 	userNS          string
 	disableLogs     bool
+	output          string
+	follow          bool
+	since           string
+	tail            string
+	waitTimeout     time.Duration
+	withUI          bool
+	instance        string
+	containerEngine string
+	allProcesses    bool
+	foreground      bool
+	supervise       bool
+	cacheDir        string
+	retries         int
+	retryBackoff    time.Duration
+	parallel        int
+	caCert          string
+	insecureReg     bool
+	httpTimeout     time.Duration
+	githubToken     string
+	releaseBaseUrl  string
+	dryRun          bool
+	merge           bool
 )
 
 func init() {
@@ -48,6 +76,18 @@ func init() {
 	selenoidCmd.AddCommand(selenoidUpdateCmd)
 	selenoidCmd.AddCommand(selenoidCleanupCmd)
 	selenoidCmd.AddCommand(selenoidStatusCmd)
+	selenoidCmd.AddCommand(selenoidLogsCmd)
+	selenoidCmd.AddCommand(selenoidRunCmd)
+	selenoidCmd.AddCommand(selenoidListCmd)
+	selenoidCmd.AddCommand(selenoidExportCmd)
+	selenoidCmd.AddCommand(selenoidInstallServiceCmd)
+	selenoidCmd.AddCommand(selenoidBundleCmd)
+	selenoidCmd.AddCommand(selenoidValidateCmd)
+
+	selenoidBundleCmd.AddCommand(selenoidBundleExportCmd)
+	selenoidBundleCmd.AddCommand(selenoidBundleImportCmd)
+
+	selenoidExportCmd.AddCommand(selenoidExportComposeCmd)
 
 	selenoidUICmd.AddCommand(selenoidDownloadUICmd)
 	selenoidUICmd.AddCommand(selenoidUIArgsCmd)
@@ -56,6 +96,8 @@ func init() {
 	selenoidUICmd.AddCommand(selenoidUpdateUICmd)
 	selenoidUICmd.AddCommand(selenoidCleanupUICmd)
 	selenoidUICmd.AddCommand(selenoidUIStatusCmd)
+	selenoidUICmd.AddCommand(selenoidUILogsCmd)
+	selenoidUICmd.AddCommand(selenoidUIInstallServiceCmd)
 }
 
 func initFlags() {
@@ -64,10 +106,12 @@ func initFlags() {
 		selenoidArgsCmd,
 		selenoidConfigureCmd,
 		selenoidStartCmd,
+		selenoidRunCmd,
 		selenoidStopCmd,
 		selenoidUpdateCmd,
 		selenoidCleanupCmd,
 		selenoidStatusCmd,
+		selenoidLogsCmd,
 		selenoidDownloadUICmd,
 		selenoidUIArgsCmd,
 		selenoidStartUICmd,
@@ -75,19 +119,34 @@ func initFlags() {
 		selenoidUpdateUICmd,
 		selenoidCleanupUICmd,
 		selenoidUIStatusCmd,
+		selenoidUILogsCmd,
+		selenoidListCmd,
+		selenoidExportComposeCmd,
+		selenoidBundleExportCmd,
+		selenoidBundleImportCmd,
+		selenoidValidateCmd,
 	} {
 		c.Flags().BoolVarP(&quiet, "quiet", "q", false, "suppress output")
 		c.Flags().BoolVarP(&useDrivers, "use-drivers", "d", false, "use drivers mode instead of Docker")
+		c.Flags().StringVarP(&containerEngine, "runtime", "", selenoid.ModeDocker, "container runtime to use: docker or podman")
+		c.Flags().StringVarP(&instance, "instance", "", "", "instance name allowing to run several Selenoid or Selenoid UI instances on one host")
 	}
 	for _, c := range []*cobra.Command{
 		selenoidDownloadCmd,
 		selenoidArgsCmd,
 		selenoidConfigureCmd,
 		selenoidStartCmd,
+		selenoidRunCmd,
 		selenoidStopCmd,
 		selenoidUpdateCmd,
 		selenoidCleanupCmd,
 		selenoidStatusCmd,
+		selenoidLogsCmd,
+		selenoidExportComposeCmd,
+		selenoidInstallServiceCmd,
+		selenoidBundleExportCmd,
+		selenoidBundleImportCmd,
+		selenoidValidateCmd,
 	} {
 		c.Flags().StringVarP(&configDir, "config-dir", "c", selenoid.GetSelenoidConfigDir(), "directory to save files")
 		c.Flags().Uint16VarP(&port, "port", "p", selenoid.DefaultPort, "override listen port")
@@ -100,6 +159,8 @@ func initFlags() {
 		selenoidUpdateUICmd,
 		selenoidCleanupUICmd,
 		selenoidUIStatusCmd,
+		selenoidUILogsCmd,
+		selenoidUIInstallServiceCmd,
 	} {
 		c.Flags().StringVarP(&uiConfigDir, "config-dir", "c", selenoid.GetSelenoidUIConfigDir(), "directory to save files")
 		c.Flags().Uint16VarP(&uiPort, "port", "p", selenoid.UIDefaultPort, "override listen port")
@@ -110,77 +171,194 @@ func initFlags() {
 		selenoidArgsCmd,
 		selenoidConfigureCmd,
 		selenoidStartCmd,
+		selenoidRunCmd,
 		selenoidUpdateCmd,
 		selenoidDownloadUICmd,
 		selenoidUIArgsCmd,
 		selenoidStartUICmd,
 		selenoidUpdateUICmd,
+		selenoidInstallServiceCmd,
+		selenoidUIInstallServiceCmd,
 	} {
 		c.Flags().StringVarP(&operatingSystem, "operating-system", "o", runtime.GOOS, "target operating system (drivers only)")
 		c.Flags().StringVarP(&arch, "architecture", "a", runtime.GOARCH, "target architecture (drivers only)")
+		c.Flags().StringVarP(&cacheDir, "cache-dir", "", selenoid.GetCacheDir(), "directory to cache downloaded drivers and binaries (drivers only)")
+		c.Flags().StringVarP(&githubToken, "github-token", "", "", "GitHub token to get release information with, "+githubTokenEnv+" environment variable is used by default (drivers only)")
+		c.Flags().StringVarP(&releaseBaseUrl, "release-base-url", "", "", "GitHub Enterprise API URL or releases index JSON URL to download binaries from (drivers only)")
 	}
 	for _, c := range []*cobra.Command{
 		selenoidDownloadCmd,
 		selenoidArgsCmd,
 		selenoidConfigureCmd,
 		selenoidStartCmd,
+		selenoidRunCmd,
 		selenoidUpdateCmd,
 		selenoidDownloadUICmd,
 		selenoidUIArgsCmd,
 		selenoidStartUICmd,
 		selenoidUpdateUICmd,
+		selenoidExportComposeCmd,
+		selenoidInstallServiceCmd,
+		selenoidUIInstallServiceCmd,
+		selenoidBundleExportCmd,
+		selenoidBundleImportCmd,
+		selenoidValidateCmd,
 	} {
 		c.Flags().StringVarP(&version, "version", "v", selenoid.Latest, "desired version; default is latest release")
 		c.Flags().StringVarP(&registry, "registry", "r", selenoid.DefaultRegistryUrl, "Docker registry to use")
+		c.Flags().StringVarP(&browsersRegistry, "browsers-registry", "", "", "Docker registry to take browser images from, the one specified with --registry is used by default")
+		c.Flags().StringVarP(&registryUsername, "registry-username", "", "", "username to authenticate in registry specified with --registry (Selenoid, Selenoid UI and video recorder images)")
+		c.Flags().BoolVarP(&registryPasswordStdin, "registry-password-stdin", "", false, "read password of registry specified with --registry from standard input")
+		c.Flags().StringVarP(&browsersRegUsername, "browsers-registry-username", "", "", "username to authenticate in registry specified with --browsers-registry")
+		c.Flags().BoolVarP(&browsersRegPassStdin, "browsers-registry-password-stdin", "", false, "read password of registry specified with --browsers-registry from standard input, goes on the next line when --registry-password-stdin is also set")
+		c.Flags().IntVarP(&retries, "retries", "", selenoid.DefaultRetries, "how many times to retry registry, GitHub and download requests failed due to transient errors")
+		c.Flags().DurationVarP(&retryBackoff, "retry-backoff", "", selenoid.DefaultRetryBackoff, "delay before the first retry, doubled after every attempt")
+		c.Flags().StringVarP(&caCert, "ca-cert", "", "", "PEM encoded CA certificates bundle to trust in addition to system ones")
+		c.Flags().BoolVarP(&insecureReg, "insecure-registry", "", false, "do not verify Docker registry TLS certificate")
+		c.Flags().DurationVarP(&httpTimeout, "http-timeout", "", selenoid.DefaultHTTPTimeout, "how much time to wait for connection and response headers of registry, GitHub and download requests")
 	}
 	for _, c := range []*cobra.Command{
 		selenoidConfigureCmd,
 		selenoidStartCmd,
+		selenoidRunCmd,
 		selenoidUpdateCmd,
+		selenoidExportComposeCmd,
+		selenoidInstallServiceCmd,
 	} {
-		c.Flags().StringVarP(&browsers, "browsers", "b", "", "semicolon separated list of browser names to process")
+		c.Flags().StringVarP(&browsers, "browsers", "b", "", "semicolon separated list of browser selectors to process, e.g. chrome:last=4;firefox:>=120,last=2;opera:exact=106.0;chrome:beta")
 		c.Flags().StringVarP(&browserEnv, "browser-env", "w", "", "override container or driver environment variables (e.g. \"KEY1=value1 KEY2=value2\")")
 		c.Flags().StringVarP(&browsersJson, "browsers-json", "j", "", "browsers JSON file to sync with")
+		c.Flags().StringVarP(&imagesCatalog, "images-catalog", "", "", "JSON file mapping browser names to images, merged with built-in catalog (Docker only)")
 		c.Flags().StringVarP(&driversInfoUrl, "drivers-info", "", selenoid.DefaultDriversInfoURL, "drivers info JSON data URL (in most cases never need to be set manually)")
 		c.Flags().BoolVarP(&skipDownload, "no-download", "n", false, "only output config file without downloading images or drivers")
 		c.Flags().IntVarP(&lastVersions, "last-versions", "l", 2, "process only last N versions (Docker only)")
 		c.Flags().IntVarP(&shmSize, "shm-size", "z", 0, "add shmSize sized in megabytes (Docker only)")
 		c.Flags().IntVarP(&tmpfs, "tmpfs", "t", 0, "add tmpfs volume sized in megabytes (Docker only)")
 		c.Flags().BoolVarP(&vnc, "vnc", "s", false, "download containers with VNC support (Docker only)")
+		c.Flags().IntVarP(&parallel, "parallel", "", selenoid.DefaultParallel, "maximum number of concurrent tag fetches and image pulls (Docker only)")
 	}
 	for _, c := range []*cobra.Command{
 		selenoidDownloadCmd,
 		selenoidArgsCmd,
 		selenoidConfigureCmd,
 		selenoidStartCmd,
+		selenoidRunCmd,
 		selenoidDownloadUICmd,
 		selenoidUIArgsCmd,
 		selenoidStartUICmd,
+		selenoidExportComposeCmd,
+		selenoidBundleExportCmd,
+		selenoidBundleImportCmd,
 	} {
 		c.Flags().BoolVarP(&force, "force", "f", false, "force action")
 	}
 	for _, c := range []*cobra.Command{
 		selenoidStopCmd,
 		selenoidStopUICmd,
+		selenoidRunCmd,
 	} {
 		c.Flags().BoolVarP(&graceful, "graceful", "", false, "do action gracefully (e.g. gracefully stop Selenoid)")
 		c.Flags().DurationVarP(&gracefulTimeout, "graceful-timeout", "", 30*time.Second, "graceful timeout value (how much time to wait for graceful action execution)")
 	}
 	for _, c := range []*cobra.Command{
 		selenoidStartCmd,
+		selenoidRunCmd,
 		selenoidUpdateCmd,
 		selenoidStartUICmd,
 		selenoidUpdateUICmd,
+		selenoidExportComposeCmd,
+		selenoidInstallServiceCmd,
+		selenoidUIInstallServiceCmd,
 	} {
 		c.Flags().StringVarP(&args, "args", "g", "", "additional service arguments (e.g. \"-limit 5\")")
 		c.Flags().StringVarP(&env, "env", "e", "", "override service environment variables (e.g. \"KEY1=value1 KEY2=value2\")")
 		c.Flags().StringVarP(&userNS, "userns", "", "", "override user namespace, similarly to \"docker run --userns host ...\" (Docker only)")
 		c.Flags().BoolVarP(&disableLogs, "disable-logs", "", false, "start with log saving feature disabled")
+		c.Flags().DurationVarP(&waitTimeout, "wait-timeout", "", 30*time.Second, "how much time to wait for service to become ready after start (0 to not wait)")
+	}
+	for _, c := range []*cobra.Command{
+		selenoidStartCmd,
+		selenoidStopCmd,
+		selenoidUpdateCmd,
+		selenoidStatusCmd,
+		selenoidStartUICmd,
+		selenoidStopUICmd,
+		selenoidUpdateUICmd,
+		selenoidUIStatusCmd,
+	} {
+		c.Flags().BoolVarP(&allProcesses, "all", "", false, "also manage processes not started by cm that match binary name (drivers only)")
+	}
+	for _, c := range []*cobra.Command{
+		selenoidStartCmd,
+		selenoidStartUICmd,
+	} {
+		c.Flags().BoolVarP(&foreground, "foreground", "", false, "keep cm attached to started binary (drivers only)")
+		c.Flags().BoolVarP(&supervise, "supervise", "", false, "restart binary exited unexpectedly, requires --foreground (drivers only)")
+	}
+	for _, c := range []*cobra.Command{
+		selenoidConfigureCmd,
+		selenoidStatusCmd,
+		selenoidUIStatusCmd,
+		selenoidListCmd,
+		selenoidValidateCmd,
+	} {
+		c.Flags().StringVarP(&output, "output", "", selenoid.OutputText, "output format: text, json or yaml")
+	}
+	for _, c := range []*cobra.Command{
+		selenoidConfigureCmd,
+		selenoidStartCmd,
+		selenoidRunCmd,
+		selenoidUpdateCmd,
+	} {
+		c.Flags().BoolVarP(&merge, "merge", "", false, "merge generated browser versions into existing browsers.json keeping manually added settings")
+	}
+	selenoidConfigureCmd.Flags().BoolVarP(&dryRun, "dry-run", "", false, "show changes of browsers.json and images to pull without applying them (Docker only)")
+	selenoidRunCmd.Flags().BoolVarP(&withUI, "with-ui", "", false, "also start Selenoid UI")
+	selenoidRunCmd.Flags().StringVarP(&uiConfigDir, "ui-config-dir", "", selenoid.GetSelenoidUIConfigDir(), "directory to save Selenoid UI files")
+	selenoidRunCmd.Flags().Uint16VarP(&uiPort, "ui-port", "", selenoid.UIDefaultPort, "override Selenoid UI listen port")
+	selenoidExportComposeCmd.Flags().Uint16VarP(&uiPort, "ui-port", "", selenoid.UIDefaultPort, "override Selenoid UI listen port")
+	for _, c := range []*cobra.Command{
+		selenoidInstallServiceCmd,
+		selenoidUIInstallServiceCmd,
+	} {
+		c.Flags().BoolVarP(&quiet, "quiet", "q", false, "suppress output")
+		c.Flags().StringVarP(&instance, "instance", "", "", "instance name allowing to run several Selenoid or Selenoid UI instances on one host")
+		c.Flags().BoolVarP(&systemService, "system", "", false, "install system-wide unit instead of user unit (requires root permissions)")
+	}
+	selenoidExportComposeCmd.Flags().StringVarP(&outputDir, "output-dir", "", ".", "directory to save docker-compose.yml and browsers.json to")
+	for _, c := range []*cobra.Command{
+		selenoidLogsCmd,
+		selenoidUILogsCmd,
+	} {
+		c.Flags().BoolVarP(&follow, "follow", "", false, "follow log output")
+		c.Flags().StringVarP(&since, "since", "", "", "show logs since timestamp (e.g. 2024-01-02T13:23:37Z) or relative (e.g. 42m)")
+		c.Flags().StringVarP(&tail, "tail", "", "all", "number of lines to show from the end of the logs")
 	}
 }
 
 func createLifecycle(configDir string, port uint16) (*selenoid.Lifecycle, error) {
-	config := selenoid.LifecycleConfig{
+	err := readRegistryPasswords()
+	if err != nil {
+		return nil, err
+	}
+	config := createLifecycleConfig(configDir, port)
+	return selenoid.NewLifecycle(&config)
+}
+
+func createLifecycleConfig(configDir string, port uint16) selenoid.LifecycleConfig {
+	token := githubToken
+	if token == "" {
+		token = os.Getenv(githubTokenEnv)
+	}
+	if instance != "" {
+		switch configDir {
+		case selenoid.GetSelenoidConfigDir():
+			configDir = selenoid.GetSelenoidInstanceConfigDir(instance)
+		case selenoid.GetSelenoidUIConfigDir():
+			configDir = selenoid.GetSelenoidUIInstanceConfigDir(instance)
+		}
+	}
+	return selenoid.LifecycleConfig{
 		Quiet:           quiet,
 		Force:           force,
 		Graceful:        graceful,
@@ -194,21 +372,82 @@ func createLifecycle(configDir string, port uint16) (*selenoid.Lifecycle, error)
 		Env:             env,
 		Port:            int(port),
 		DisableLogs:     disableLogs,
+		WaitTimeout:     waitTimeout,
+		Instance:        instance,
+		Retries:         retries,
+		RetryBackoff:    retryBackoff,
+		Parallel:        parallel,
+		Merge:           merge,
+		CACert:          caCert,
+		HTTPTimeout:     httpTimeout,
 
-		LastVersions: lastVersions,
-		RegistryUrl:  registry,
-		BrowsersJson: browsersJson,
-		ShmSize:      shmSize,
-		Tmpfs:        tmpfs,
-		VNC:          vnc,
-		UserNS:       userNS,
+		Runtime:             containerEngine,
+		LastVersions:        lastVersions,
+		RegistryUrl:         registry,
+		BrowsersRegistryUrl: browsersRegistry,
+		RegistryUsername:    registryUsername,
+		RegistryPassword:    registryPassword,
+		BrowsersUsername:    browsersRegUsername,
+		BrowsersPassword:    browsersRegPassword,
+		InsecureRegistry:    insecureReg,
+		BrowsersJson:        browsersJson,
+		ImagesCatalog:       imagesCatalog,
+		ShmSize:             shmSize,
+		Tmpfs:               tmpfs,
+		VNC:                 vnc,
+		UserNS:              userNS,
 
 		DriversInfoUrl: driversInfoUrl,
+		AllProcesses:   allProcesses,
+		Foreground:     foreground,
+		Supervise:      supervise,
+		CacheDir:       cacheDir,
+		ReleaseBaseUrl: releaseBaseUrl,
+		GithubToken:    token,
 		OS:             operatingSystem,
 		Arch:           arch,
 		Version:        version,
 	}
-	return selenoid.NewLifecycle(&config)
+}
+
+// readRegistryPasswords reads passwords from standard input once as several lifecycles can be created by one command.
+// When passwords of both registries are requested, --registry password goes on the first line and --browsers-registry one on the second.
+func readRegistryPasswords() error {
+	type request struct {
+		flag     string
+		password *string
+	}
+	var requests []request
+	if registryPasswordStdin && registryPassword == "" {
+		requests = append(requests, request{"--registry-password-stdin", &registryPassword})
+		if registryUsername == "" {
+			return errors.New("--registry-password-stdin requires --registry-username")
+		}
+	}
+	if browsersRegPassStdin && browsersRegPassword == "" {
+		requests = append(requests, request{"--browsers-registry-password-stdin", &browsersRegPassword})
+		if browsersRegUsername == "" {
+			return errors.New("--browsers-registry-password-stdin requires --browsers-registry-username")
+		}
+	}
+	if len(requests) == 0 {
+		return nil
+	}
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return fmt.Errorf("failed to read registry password: %v", err)
+	}
+	passwords := []string{strings.TrimRight(string(data), "\r\n")}
+	if len(requests) > 1 {
+		passwords = strings.Split(passwords[0], "\n")
+	}
+	for i, r := range requests {
+		if i >= len(passwords) || strings.TrimRight(passwords[i], "\r") == "" {
+			return fmt.Errorf("empty registry password for %s", r.flag)
+		}
+		*r.password = strings.TrimRight(passwords[i], "\r")
+	}
+	return nil
 }
 
 var selenoidCmd = &cobra.Command{
@@ -220,5 +459,5 @@ var selenoidCmd = &cobra.Command{
 }
 
 func stderr(format string, a ...interface{}) {
-	_, _ = fmt.Fprintf(os.Stderr, format, a)
+	_, _ = fmt.Fprintf(os.Stderr, format, a...)
 }
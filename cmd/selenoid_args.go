@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"os"
 
 	"github.com/aerokube/cm/selenoid"
@@ -11,20 +12,22 @@ var selenoidArgsCmd = &cobra.Command{
 	Use:   "args",
 	Short: "Shows Selenoid available args",
 	Run: func(cmd *cobra.Command, args []string) {
-		argsImpl(uiConfigDir, uiPort, func(lc *selenoid.Lifecycle) error {
-			return lc.PrintArgs()
+		argsImpl(uiConfigDir, uiPort, func(ctx context.Context, lc *selenoid.Lifecycle) error {
+			return lc.PrintArgs(ctx)
 		}, force)
 	},
 }
 
-func argsImpl(configDir string, port uint16, argsAction func(*selenoid.Lifecycle) error, force bool) {
+func argsImpl(configDir string, port uint16, argsAction func(context.Context, *selenoid.Lifecycle) error, force bool) {
 	lifecycle, err := createLifecycle(configDir, port)
 	if err != nil {
 		stderr("Failed to initialize: %v\n", err)
 		os.Exit(1)
 	}
 	lifecycle.Force = force
-	err = argsAction(lifecycle)
+	ctx, cancel := commandContext()
+	defer cancel()
+	err = argsAction(ctx, lifecycle)
 	if err != nil {
 		lifecycle.Errorf("Failed to print args: %v", err)
 		os.Exit(1)
@@ -0,0 +1,56 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/aerokube/cm/selenoid"
+	"github.com/spf13/cobra"
+)
+
+var selenoidBundleCmd = &cobra.Command{
+	Use:   "bundle",
+	Short: "Export or import offline bundle with Selenoid, browser images and configuration",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Usage()
+	},
+}
+
+var selenoidBundleExportCmd = &cobra.Command{
+	Use:   "export <file>",
+	Short: "Save Selenoid, Selenoid UI, video recorder and browser images with browsers.json to file",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		bundleImpl(func(ctx context.Context, lc *selenoid.Lifecycle) error {
+			return lc.ExportBundle(ctx, args[0])
+		}, "Failed to export bundle: %v\n")
+	},
+}
+
+var selenoidBundleImportCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Load images and browsers.json from file without accessing registry",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		bundleImpl(func(ctx context.Context, lc *selenoid.Lifecycle) error {
+			return lc.ImportBundle(ctx, args[0])
+		}, "Failed to import bundle: %v\n")
+	},
+}
+
+func bundleImpl(bundleAction func(context.Context, *selenoid.Lifecycle) error, errorMessage string) {
+	lifecycle, err := createLifecycle(configDir, port)
+	if err != nil {
+		stderr("Failed to initialize: %v\n", err)
+		os.Exit(1)
+	}
+	defer lifecycle.Close()
+	ctx, cancel := commandContext()
+	defer cancel()
+	err = bundleAction(ctx, lifecycle)
+	if err != nil {
+		lifecycle.Errorf(errorMessage, err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"os"
 
 	"github.com/aerokube/cm/selenoid"
@@ -11,26 +12,28 @@ var selenoidCleanupCmd = &cobra.Command{
 	Use:   "cleanup",
 	Short: "Remove Selenoid traces",
 	Run: func(cmd *cobra.Command, args []string) {
-		cleanupImpl(configDir, port, func(lc *selenoid.Lifecycle) error {
-			return lc.Stop()
+		cleanupImpl(configDir, port, func(ctx context.Context, lc *selenoid.Lifecycle) error {
+			return lc.Stop(ctx)
 		})
 	},
 }
 
-func cleanupImpl(configDir string, port uint16, stopAction func(*selenoid.Lifecycle) error) {
+func cleanupImpl(configDir string, port uint16, stopAction func(context.Context, *selenoid.Lifecycle) error) {
 	lifecycle, err := createLifecycle(configDir, port)
 	if err != nil {
 		stderr("Failed to initialize: %v\n", err)
 		os.Exit(1)
 	}
 
-	err = stopAction(lifecycle)
+	ctx, cancel := commandContext()
+	defer cancel()
+	err = stopAction(ctx, lifecycle)
 	if err != nil {
 		lifecycle.Errorf("Failed to stop: %v\n", err)
 		os.Exit(1)
 	}
 
-	err = os.RemoveAll(configDir)
+	err = os.RemoveAll(lifecycle.Config.ConfigDir)
 	if err != nil {
 		lifecycle.Errorf("Failed to remove configuration directory: %v\n", err)
 		os.Exit(1)
@@ -3,6 +3,7 @@ package cmd
 import (
 	"os"
 
+	"github.com/aerokube/cm/selenoid"
 	"github.com/spf13/cobra"
 )
 
@@ -10,12 +11,29 @@ var selenoidConfigureCmd = &cobra.Command{
 	Use:   "configure",
 	Short: "Create Selenoid configuration file and download dependencies",
 	Run: func(cmd *cobra.Command, args []string) {
+		if !dryRun && cmd.Flags().Changed("output") {
+			stderr("--output can only be used with --dry-run\n")
+			os.Exit(1)
+		}
+		if dryRun && output != selenoid.OutputText {
+			quiet = true
+		}
 		lifecycle, err := createLifecycle(configDir, port)
 		if err != nil {
 			stderr("Failed to initialize: %v\n", err)
 			os.Exit(1)
 		}
-		err = lifecycle.Configure()
+		ctx, cancel := commandContext()
+		defer cancel()
+		if dryRun {
+			err = lifecycle.PlanConfigure(ctx, output)
+			if err != nil {
+				lifecycle.Errorf("Failed to plan Selenoid configuration: %v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		}
+		err = lifecycle.Configure(ctx)
 		if err != nil {
 			lifecycle.Errorf("Failed to configure Selenoid: %v\n", err)
 			os.Exit(1)
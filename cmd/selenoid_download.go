@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"os"
 
 	"github.com/aerokube/cm/selenoid"
@@ -11,19 +12,21 @@ var selenoidDownloadCmd = &cobra.Command{
 	Use:   "download",
 	Short: "Download Selenoid latest or specified release",
 	Run: func(cmd *cobra.Command, args []string) {
-		downloadImpl(configDir, port, func(lc *selenoid.Lifecycle) error {
-			return lc.Download()
+		downloadImpl(configDir, port, func(ctx context.Context, lc *selenoid.Lifecycle) error {
+			return lc.Download(ctx)
 		})
 	},
 }
 
-func downloadImpl(configDir string, port uint16, downloadAction func(*selenoid.Lifecycle) error) {
+func downloadImpl(configDir string, port uint16, downloadAction func(context.Context, *selenoid.Lifecycle) error) {
 	lifecycle, err := createLifecycle(configDir, port)
 	if err != nil {
 		stderr("Failed to initialize: %v\n", err)
 		os.Exit(1)
 	}
-	err = downloadAction(lifecycle)
+	ctx, cancel := commandContext()
+	defer cancel()
+	err = downloadAction(ctx, lifecycle)
 	if err != nil {
 		lifecycle.Errorf("Failed to download: %v\n", err)
 		os.Exit(1)
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var outputDir string
+
+var selenoidExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export Selenoid setup to other formats",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return cmd.Usage()
+	},
+}
+
+var selenoidExportComposeCmd = &cobra.Command{
+	Use:   "compose",
+	Short: "Export Selenoid and Selenoid UI containers as docker-compose.yml with browsers.json",
+	Run: func(cmd *cobra.Command, args []string) {
+		lifecycle, err := createLifecycle(configDir, port)
+		if err != nil {
+			stderr("Failed to initialize: %v\n", err)
+			os.Exit(1)
+		}
+		defer lifecycle.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		err = lifecycle.ExportCompose(ctx, outputDir, int(uiPort))
+		if err != nil {
+			lifecycle.Errorf("Failed to export compose file: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	},
+}
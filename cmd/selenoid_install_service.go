@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/aerokube/cm/selenoid"
+	"github.com/spf13/cobra"
+)
+
+var systemService bool
+
+var selenoidInstallServiceCmd = &cobra.Command{
+	Use:   "install-service",
+	Short: "Install and enable systemd unit running Selenoid binary",
+	Run: func(cmd *cobra.Command, args []string) {
+		installServiceImpl(configDir, port, func(ctx context.Context, lc *selenoid.Lifecycle) error {
+			return lc.InstallService(ctx, systemService)
+		})
+	},
+}
+
+// Systemd units are only used in drivers mode as containers already have restart policy
+func installServiceImpl(configDir string, port uint16, installAction func(context.Context, *selenoid.Lifecycle) error) {
+	useDrivers = true
+	lifecycle, err := createLifecycle(configDir, port)
+	if err != nil {
+		stderr("Failed to initialize: %v\n", err)
+		os.Exit(1)
+	}
+	ctx, cancel := commandContext()
+	defer cancel()
+	err = installAction(ctx, lifecycle)
+	if err != nil {
+		lifecycle.Errorf("Failed to install service: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/aerokube/cm/selenoid"
+	"github.com/spf13/cobra"
+)
+
+var selenoidListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Shows all Selenoid and Selenoid UI instances managed by cm",
+	Run: func(cmd *cobra.Command, args []string) {
+		if output != selenoid.OutputText {
+			quiet = true
+		}
+		lifecycle, err := createLifecycle(configDir, port)
+		if err != nil {
+			stderr("Failed to initialize: %v\n", err)
+			os.Exit(1)
+		}
+		err = lifecycle.List(output)
+		if err != nil {
+			lifecycle.Errorf("Failed to list instances: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	},
+}
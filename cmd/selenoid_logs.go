@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"context"
+	"os"
+
+	"github.com/aerokube/cm/selenoid"
+	"github.com/spf13/cobra"
+)
+
+var selenoidLogsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Shows Selenoid logs",
+	Run: func(cmd *cobra.Command, args []string) {
+		logsImpl(configDir, port, func(ctx context.Context, lc *selenoid.Lifecycle, opts *selenoid.LogsOptions) error {
+			return lc.Logs(ctx, opts)
+		})
+	},
+}
+
+func logsImpl(configDir string, port uint16, logsAction func(context.Context, *selenoid.Lifecycle, *selenoid.LogsOptions) error) {
+	lifecycle, err := createLifecycle(configDir, port)
+	if err != nil {
+		stderr("Failed to initialize: %v\n", err)
+		os.Exit(1)
+	}
+	opts := &selenoid.LogsOptions{
+		Follow: follow,
+		Since:  since,
+		Tail:   tail,
+	}
+	ctx, cancel := commandContext()
+	defer cancel()
+	err = logsAction(ctx, lifecycle, opts)
+	if err != nil {
+		lifecycle.Errorf("Failed to show logs: %v\n", err)
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
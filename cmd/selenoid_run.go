@@ -0,0 +1,177 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	"github.com/aerokube/cm/selenoid"
+	"github.com/spf13/cobra"
+)
+
+const (
+	selenoidUrlEnv   = "SELENOID_URL"
+	selenoidUIUrlEnv = "SELENOID_UI_URL"
+	interruptedCode  = 130
+	// signaledCodeBase is added to signal number when command was killed by a signal, as shells do
+	signaledCodeBase = 128
+)
+
+var selenoidRunCmd = &cobra.Command{
+	Use:   "run -- <command> [args...]",
+	Short: "Start Selenoid, run command and stop Selenoid",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		os.Exit(runImpl(args))
+	},
+}
+
+// runService is Selenoid or Selenoid UI started for the time of command execution
+type runService struct {
+	name      string
+	envName   string
+	isRunning func() bool
+	start     func(context.Context) error
+	stop      func(context.Context) error
+	url       func() string
+}
+
+func runImpl(command []string) int {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	ctx, cancel := commandContext()
+	defer cancel()
+
+	lifecycle, err := createLifecycle(configDir, port)
+	if err != nil {
+		stderr("Failed to initialize: %v\n", err)
+		return 1
+	}
+	defer lifecycle.Close()
+	services := []runService{{
+		name:      "Selenoid",
+		envName:   selenoidUrlEnv,
+		isRunning: lifecycle.IsRunning,
+		start:     lifecycle.Start,
+		stop:      lifecycle.Stop,
+		url:       lifecycle.URL,
+	}}
+	if withUI {
+		uiLifecycle, err := createUILifecycle()
+		if err != nil {
+			lifecycle.Errorf("Failed to initialize Selenoid UI: %v\n", err)
+			return 1
+		}
+		defer uiLifecycle.Close()
+		services = append(services, runService{
+			name:      "Selenoid UI",
+			envName:   selenoidUIUrlEnv,
+			isRunning: uiLifecycle.IsUIRunning,
+			start:     uiLifecycle.StartUI,
+			stop:      uiLifecycle.StopUI,
+			url:       uiLifecycle.UIURL,
+		})
+	}
+	return runWithServices(ctx, &lifecycle.Logger, signals, services, command)
+}
+
+// runWithServices starts services, runs command and returns its exit code.
+// Only services that were not running before are stopped, so that a shared Selenoid instance is kept.
+func runWithServices(ctx context.Context, logger *selenoid.Logger, signals <-chan os.Signal, services []runService, command []string) int {
+	var started []runService
+	// Services are stopped even when command was interrupted or timed out
+	teardown := func() {
+		stopCtx := context.WithoutCancel(ctx)
+		for i := len(started) - 1; i >= 0; i-- {
+			if err := started[i].stop(stopCtx); err != nil {
+				logger.Errorf("Failed to stop %s: %v\n", started[i].name, err)
+			}
+		}
+	}
+
+	env := os.Environ()
+	for _, s := range services {
+		wasRunning := s.isRunning()
+		err := s.start(ctx)
+		if !wasRunning {
+			started = append(started, s)
+		}
+		if err != nil {
+			logger.Errorf("Failed to start %s: %v\n", s.name, err)
+			teardown()
+			return 1
+		}
+		env = append(env, s.envName+"="+s.url())
+	}
+	select {
+	case <-signals:
+		teardown()
+		return interruptedCode
+	case <-ctx.Done():
+		teardown()
+		return interruptedCode
+	default:
+	}
+
+	logger.Titlef("Running %v...", command)
+	child := exec.Command(command[0], command[1:]...)
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+	child.Env = env
+	err := child.Start()
+	if err != nil {
+		logger.Errorf("Failed to run command: %v\n", err)
+		teardown()
+		return 1
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- child.Wait()
+	}()
+	ctxDone := ctx.Done()
+	for {
+		select {
+		case s := <-signals:
+			_ = child.Process.Signal(s)
+		case <-ctxDone:
+			ctxDone = nil
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				logger.Errorf("Command did not complete in %v, killing it\n", timeout)
+				_ = child.Process.Kill()
+			}
+		case err = <-done:
+			teardown()
+			return exitCode(err)
+		}
+	}
+}
+
+// Selenoid specific args, environment and version are not applicable to Selenoid UI
+func createUILifecycle() (*selenoid.Lifecycle, error) {
+	config := createLifecycleConfig(uiConfigDir, uiPort)
+	config.Args = ""
+	config.Env = ""
+	config.Version = selenoid.Latest
+	return selenoid.NewLifecycle(&config)
+}
+
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if exitErr.ExitCode() >= 0 {
+			return exitErr.ExitCode()
+		}
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			return signaledCodeBase + int(status.Signal())
+		}
+	}
+	return 1
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/aerokube/cm/selenoid"
+	assert "github.com/stretchr/testify/require"
+)
+
+type fakeService struct {
+	running bool
+	started int
+	stopped int
+	err     error
+}
+
+func (s *fakeService) runService(name string, envName string) runService {
+	return runService{
+		name:      name,
+		envName:   envName,
+		isRunning: func() bool { return s.running },
+		start: func(context.Context) error {
+			s.started++
+			if s.err == nil {
+				s.running = true
+			}
+			return s.err
+		},
+		stop: func(context.Context) error {
+			s.stopped++
+			s.running = false
+			return nil
+		},
+		url: func() string { return "http://localhost:4444/" + name },
+	}
+}
+
+func testRun(services []runService, command ...string) int {
+	return runWithServices(context.Background(), &selenoid.Logger{Quiet: true}, make(chan os.Signal), services, command)
+}
+
+func TestRunPropagatesExitCode(t *testing.T) {
+	for command, expected := range map[string]int{"exit 0": 0, "exit 3": 3, "kill -KILL $$": 137, "kill -TERM $$": 143, `test "$SELENOID_URL" = "http://localhost:4444/Selenoid"`: 0} {
+		s := &fakeService{}
+		assert.Equal(t, expected, testRun([]runService{s.runService("Selenoid", selenoidUrlEnv)}, "sh", "-c", command), command)
+		assert.Equal(t, 1, s.stopped)
+	}
+	s := &fakeService{}
+	assert.Equal(t, 1, testRun([]runService{s.runService("Selenoid", selenoidUrlEnv)}, "/missing/command"))
+	assert.Equal(t, 1, s.stopped)
+}
+
+func TestRunKeepsRunningServices(t *testing.T) {
+	shared := &fakeService{running: true}
+	ui := &fakeService{}
+	code := testRun([]runService{shared.runService("Selenoid", selenoidUrlEnv), ui.runService("Selenoid UI", selenoidUIUrlEnv)}, "sh", "-c", "exit 0")
+	assert.Equal(t, 0, code)
+	assert.Equal(t, 1, shared.started)
+	assert.Equal(t, 0, shared.stopped)
+	assert.True(t, shared.running)
+	assert.Equal(t, 1, ui.stopped)
+}
+
+func TestRunStopsStartedServicesOnFailure(t *testing.T) {
+	s := &fakeService{}
+	ui := &fakeService{err: errors.New("failed")}
+	code := testRun([]runService{s.runService("Selenoid", selenoidUrlEnv), ui.runService("Selenoid UI", selenoidUIUrlEnv)}, "sh", "-c", "exit 0")
+	assert.Equal(t, 1, code)
+	assert.Equal(t, 1, s.stopped)
+	assert.Equal(t, 1, ui.stopped)
+}
+
+func TestRunInterrupted(t *testing.T) {
+	s := &fakeService{}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	code := runWithServices(ctx, &selenoid.Logger{Quiet: true}, make(chan os.Signal), []runService{s.runService("Selenoid", selenoidUrlEnv)}, []string{"sh", "-c", "exit 0"})
+	assert.Equal(t, interruptedCode, code)
+	assert.Equal(t, 1, s.stopped)
+}
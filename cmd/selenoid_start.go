@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"os"
 
 	"github.com/aerokube/cm/selenoid"
@@ -11,20 +12,22 @@ var selenoidStartCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start Selenoid",
 	Run: func(cmd *cobra.Command, args []string) {
-		startImpl(configDir, port, func(lc *selenoid.Lifecycle) error {
-			return lc.Start()
+		startImpl(configDir, port, func(ctx context.Context, lc *selenoid.Lifecycle) error {
+			return lc.Start(ctx)
 		}, force)
 	},
 }
 
-func startImpl(configDir string, port uint16, startAction func(*selenoid.Lifecycle) error, force bool) {
+func startImpl(configDir string, port uint16, startAction func(context.Context, *selenoid.Lifecycle) error, force bool) {
 	lifecycle, err := createLifecycle(configDir, port)
 	if err != nil {
 		stderr("Failed to initialize: %v\n", err)
 		os.Exit(1)
 	}
 	lifecycle.Force = force
-	err = startAction(lifecycle)
+	ctx, cancel := commandContext()
+	defer cancel()
+	err = startAction(ctx, lifecycle)
 	if err != nil {
 		lifecycle.Errorf("Failed to start: %v\n", err)
 		os.Exit(1)
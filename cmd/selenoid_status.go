@@ -3,6 +3,7 @@ package cmd
 import (
 	"os"
 
+	"github.com/aerokube/cm/selenoid"
 	"github.com/spf13/cobra"
 )
 
@@ -10,11 +11,24 @@ var selenoidStatusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Shows Selenoid configuration status",
 	Run: func(cmd *cobra.Command, args []string) {
-		lifecycle, err := createLifecycle(configDir, port)
-		if err != nil {
-			stderr("Failed to initialize: %v\n", err)
-			os.Exit(1)
-		}
-		lifecycle.Status()
+		statusImpl(configDir, port, func(lc *selenoid.Lifecycle) error {
+			return lc.Status(output)
+		})
 	},
 }
+
+func statusImpl(configDir string, port uint16, statusAction func(*selenoid.Lifecycle) error) {
+	if output != selenoid.OutputText {
+		quiet = true
+	}
+	lifecycle, err := createLifecycle(configDir, port)
+	if err != nil {
+		stderr("Failed to initialize: %v\n", err)
+		os.Exit(1)
+	}
+	err = statusAction(lifecycle)
+	if err != nil {
+		lifecycle.Errorf("Failed to show status: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"os"
 
 	"github.com/aerokube/cm/selenoid"
@@ -11,19 +12,21 @@ var selenoidStopCmd = &cobra.Command{
 	Use:   "stop",
 	Short: "Stop Selenoid",
 	Run: func(cmd *cobra.Command, args []string) {
-		stopImpl(configDir, port, func(lc *selenoid.Lifecycle) error {
-			return lc.Stop()
+		stopImpl(configDir, port, func(ctx context.Context, lc *selenoid.Lifecycle) error {
+			return lc.Stop(ctx)
 		})
 	},
 }
 
-func stopImpl(configDir string, port uint16, stopAction func(*selenoid.Lifecycle) error) {
+func stopImpl(configDir string, port uint16, stopAction func(context.Context, *selenoid.Lifecycle) error) {
 	lifecycle, err := createLifecycle(configDir, port)
 	if err != nil {
 		stderr("Failed to initialize: %v\n", err)
 		os.Exit(1)
 	}
-	err = stopAction(lifecycle)
+	ctx, cancel := commandContext()
+	defer cancel()
+	err = stopAction(ctx, lifecycle)
 	if err != nil {
 		lifecycle.Errorf("Failed to stop: %v\n", err)
 		os.Exit(1)
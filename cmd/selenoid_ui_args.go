@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"github.com/aerokube/cm/selenoid"
 	"github.com/spf13/cobra"
 )
@@ -9,8 +10,8 @@ var selenoidUIArgsCmd = &cobra.Command{
 	Use:   "args",
 	Short: "Shows Selenoid UI available args",
 	Run: func(cmd *cobra.Command, args []string) {
-		argsImpl(uiConfigDir, uiPort, func(lc *selenoid.Lifecycle) error {
-			return lc.PrintUIArgs()
+		argsImpl(uiConfigDir, uiPort, func(ctx context.Context, lc *selenoid.Lifecycle) error {
+			return lc.PrintUIArgs(ctx)
 		}, force)
 	},
 }
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"github.com/aerokube/cm/selenoid"
 	"github.com/spf13/cobra"
 )
@@ -9,8 +10,8 @@ var selenoidCleanupUICmd = &cobra.Command{
 	Use:   "cleanup",
 	Short: "Remove Selenoid UI traces",
 	Run: func(cmd *cobra.Command, args []string) {
-		cleanupImpl(uiConfigDir, uiPort, func(lc *selenoid.Lifecycle) error {
-			return lc.StopUI()
+		cleanupImpl(uiConfigDir, uiPort, func(ctx context.Context, lc *selenoid.Lifecycle) error {
+			return lc.StopUI(ctx)
 		})
 	},
 }
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"github.com/aerokube/cm/selenoid"
 	"github.com/spf13/cobra"
 )
@@ -9,8 +10,8 @@ var selenoidDownloadUICmd = &cobra.Command{
 	Use:   "download",
 	Short: "Download latest or specified release of Selenoid UI",
 	Run: func(cmd *cobra.Command, args []string) {
-		downloadImpl(uiConfigDir, uiPort, func(lc *selenoid.Lifecycle) error {
-			return lc.DownloadUI()
+		downloadImpl(uiConfigDir, uiPort, func(ctx context.Context, lc *selenoid.Lifecycle) error {
+			return lc.DownloadUI(ctx)
 		})
 	},
 }
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"context"
+	"github.com/aerokube/cm/selenoid"
+	"github.com/spf13/cobra"
+)
+
+var selenoidUIInstallServiceCmd = &cobra.Command{
+	Use:   "install-service",
+	Short: "Install and enable systemd unit running Selenoid UI binary",
+	Run: func(cmd *cobra.Command, args []string) {
+		installServiceImpl(uiConfigDir, uiPort, func(ctx context.Context, lc *selenoid.Lifecycle) error {
+			return lc.InstallUIService(ctx, systemService)
+		})
+	},
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"context"
+	"github.com/aerokube/cm/selenoid"
+	"github.com/spf13/cobra"
+)
+
+var selenoidUILogsCmd = &cobra.Command{
+	Use:   "logs",
+	Short: "Shows Selenoid UI logs",
+	Run: func(cmd *cobra.Command, args []string) {
+		logsImpl(uiConfigDir, uiPort, func(ctx context.Context, lc *selenoid.Lifecycle, opts *selenoid.LogsOptions) error {
+			return lc.UILogs(ctx, opts)
+		})
+	},
+}
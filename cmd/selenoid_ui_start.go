@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"github.com/aerokube/cm/selenoid"
 	"github.com/spf13/cobra"
 )
@@ -9,8 +10,8 @@ var selenoidStartUICmd = &cobra.Command{
 	Use:   "start",
 	Short: "Start Selenoid UI",
 	Run: func(cmd *cobra.Command, args []string) {
-		startImpl(uiConfigDir, uiPort, func(lc *selenoid.Lifecycle) error {
-			return lc.StartUI()
+		startImpl(uiConfigDir, uiPort, func(ctx context.Context, lc *selenoid.Lifecycle) error {
+			return lc.StartUI(ctx)
 		}, force)
 	},
 }
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"github.com/aerokube/cm/selenoid"
 	"github.com/spf13/cobra"
 )
@@ -9,8 +10,8 @@ var selenoidStopUICmd = &cobra.Command{
 	Use:   "stop",
 	Short: "Stop Selenoid UI",
 	Run: func(cmd *cobra.Command, args []string) {
-		stopImpl(uiConfigDir, uiPort, func(lc *selenoid.Lifecycle) error {
-			return lc.StopUI()
+		stopImpl(uiConfigDir, uiPort, func(ctx context.Context, lc *selenoid.Lifecycle) error {
+			return lc.StopUI(ctx)
 		})
 	},
 }
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"github.com/aerokube/cm/selenoid"
 	"github.com/spf13/cobra"
 )
@@ -9,8 +10,8 @@ var selenoidUpdateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "Update Selenoid (download latest Selenoid, configure and start)",
 	Run: func(cmd *cobra.Command, args []string) {
-		startImpl(configDir, port, func(lc *selenoid.Lifecycle) error {
-			return lc.Start()
+		startImpl(configDir, port, func(ctx context.Context, lc *selenoid.Lifecycle) error {
+			return lc.Start(ctx)
 		}, true)
 	},
 }
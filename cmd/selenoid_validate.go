@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/aerokube/cm/selenoid"
+	"github.com/spf13/cobra"
+)
+
+var selenoidValidateCmd = &cobra.Command{
+	Use:   "validate [browsers.json]",
+	Short: "Validate Selenoid configuration file against the environment",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if output != selenoid.OutputText {
+			quiet = true
+		}
+		lifecycle, err := createLifecycle(configDir, port)
+		if err != nil {
+			stderr("Failed to initialize: %v\n", err)
+			os.Exit(1)
+		}
+		defer lifecycle.Close()
+		ctx, cancel := commandContext()
+		defer cancel()
+		path := ""
+		if len(args) > 0 {
+			path = args[0]
+		}
+		err = lifecycle.Validate(ctx, path, output)
+		if err != nil {
+			lifecycle.Errorf("Validation failed: %v\n", err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	},
+}
@@ -19,6 +19,7 @@ require (
 	github.com/stretchr/testify v1.9.0
 	golang.org/x/text v0.16.0
 	gopkg.in/cheggaaa/pb.v1 v1.0.28
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -59,5 +60,4 @@ require (
 	google.golang.org/grpc v1.64.1 // indirect
 	google.golang.org/protobuf v1.34.1 // indirect
 	gopkg.in/check.v1 v1.0.0-20201130134442-10cb98267c6c // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,98 @@
+package rewriter
+
+import (
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Progress renders progress of several concurrent operations (e.g. image pulls) as one block per operation.
+// Every block has a title line followed by lines of its items (e.g. image layers) until the block is finished.
+// It is safe for concurrent use.
+type Progress struct {
+	mu     sync.Mutex
+	w      *Rewriter
+	order  []string
+	blocks map[string]*block
+}
+
+type block struct {
+	status string
+	done   bool
+	items  []string
+	lines  map[string]string
+}
+
+// NewProgress returns a new Progress writing to w
+func NewProgress(w io.Writer) *Progress {
+	return &Progress{
+		w:      New(w),
+		blocks: make(map[string]*block),
+	}
+}
+
+func (p *Progress) getBlock(name string) *block {
+	b, ok := p.blocks[name]
+	if !ok {
+		b = &block{lines: make(map[string]string)}
+		p.blocks[name] = b
+		p.order = append(p.order, name)
+	}
+	return b
+}
+
+// Start adds a block with specified status if it does not exist
+func (p *Progress) Start(name string, status string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.getBlock(name).status = status
+	p.render()
+}
+
+// Update sets line of block item, empty line removes the item
+func (p *Progress) Update(name string, item string, line string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	b := p.getBlock(name)
+	if _, ok := b.lines[item]; !ok && line != "" {
+		b.items = append(b.items, item)
+	}
+	if line == "" {
+		delete(b.lines, item)
+		for i, it := range b.items {
+			if it == item {
+				b.items = append(b.items[:i], b.items[i+1:]...)
+				break
+			}
+		}
+	} else {
+		b.lines[item] = line
+	}
+	p.render()
+}
+
+// Finish collapses block to its title line with final status
+func (p *Progress) Finish(name string, status string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	b := p.getBlock(name)
+	b.status = status
+	b.done = true
+	b.items = nil
+	b.lines = make(map[string]string)
+	p.render()
+}
+
+func (p *Progress) render() {
+	for _, name := range p.order {
+		b := p.blocks[name]
+		_, _ = fmt.Fprintf(p.w, "%s: %s\n", name, b.status)
+		if b.done {
+			continue
+		}
+		for _, item := range b.items {
+			_, _ = fmt.Fprintf(p.w, "\t[%s]: %s\n", item, b.lines[item])
+		}
+	}
+	_ = p.w.Flush()
+}
@@ -3,6 +3,7 @@ package rewriter
 import (
 	"bytes"
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -18,3 +19,24 @@ func TestWriter(t *testing.T) {
 		t.Fatalf("want %q, got %q", want, b.String())
 	}
 }
+
+func TestProgress(t *testing.T) {
+	b := &bytes.Buffer{}
+	p := NewProgress(b)
+	p.Start("first", "waiting")
+	p.Start("second", "pulling")
+	p.Update("second", "layer", "Downloading")
+	b.Reset()
+	p.Finish("first", "pulled")
+	want := clearCursorAndLine + clearCursorAndLine + clearCursorAndLine + "first: pulled\nsecond: pulling\n\t[layer]: Downloading\n"
+	if b.String() != want {
+		t.Fatalf("want %q, got %q", want, b.String())
+	}
+	b.Reset()
+	p.Update("second", "layer", "")
+	p.Finish("second", "pulled")
+	want = strings.Repeat(clearCursorAndLine, 3) + "first: pulled\nsecond: pulling\n" + strings.Repeat(clearCursorAndLine, 2) + "first: pulled\nsecond: pulled\n"
+	if b.String() != want {
+		t.Fatalf("want %q, got %q", want, b.String())
+	}
+}
@@ -1,11 +1,14 @@
 package selenoid
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/user"
 	"path/filepath"
+	"regexp"
 	"time"
 
 	"github.com/fatih/color"
@@ -13,34 +16,65 @@ import (
 )
 
 type StatusProvider interface {
-	Status()
-	UIStatus()
+	Status() *ServiceStatus
+	UIStatus() *ServiceStatus
+}
+
+type InstanceLister interface {
+	List() ([]*ServiceStatus, error)
+}
+
+type LogsProvider interface {
+	Logs(ctx context.Context, w io.Writer, opts *LogsOptions) error
+	UILogs(ctx context.Context, w io.Writer, opts *LogsOptions) error
+}
+
+type ComposeExporter interface {
+	ExportCompose(ctx context.Context, outputDir string, uiPort int) error
+}
+
+type BundleManager interface {
+	ExportBundle(ctx context.Context, path string) error
+	ImportBundle(ctx context.Context, path string) error
+}
+
+type ServiceInstaller interface {
+	InstallService(ctx context.Context, system bool) error
+	InstallUIService(ctx context.Context, system bool) error
 }
 
 type ArgsProvider interface {
-	PrintArgs() error
-	PrintUIArgs() error
+	PrintArgs(ctx context.Context) error
+	PrintUIArgs(ctx context.Context) error
 }
 
 type Downloadable interface {
 	IsDownloaded() bool
-	Download() (string, error)
+	Download(ctx context.Context) (string, error)
 	IsUIDownloaded() bool
-	DownloadUI() (string, error)
+	DownloadUI(ctx context.Context) (string, error)
 }
 
 type Configurable interface {
 	IsConfigured() bool
-	Configure() (*SelenoidConfig, error)
+	Configure(ctx context.Context) (*SelenoidConfig, error)
+}
+
+type ConfigPlanner interface {
+	PlanConfigure(ctx context.Context) (*ConfigPlan, error)
+}
+
+type ConfigValidator interface {
+	Validate(ctx context.Context, configPath string) (*ValidationReport, error)
 }
 
 type Runnable interface {
 	IsRunning() bool
-	Start() error
-	Stop() error
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
 	IsUIRunning() bool
-	StartUI() error
-	StopUI() error
+	StartUI(ctx context.Context) error
+	StopUI(ctx context.Context) error
 }
 
 type Logger struct {
@@ -132,6 +166,24 @@ type LogsAware struct {
 	DisableLogs bool
 }
 
+type InstanceAware struct {
+	Instance string
+}
+
+// instanceName namespaces container, network or systemd unit name with instance name.
+// Instance name is used as a prefix, so that names of different instances never clash
+// with each other or with default names (e.g. instance "ui" gives "ui_selenoid", not "selenoid-ui").
+func (i *InstanceAware) instanceName(name string) string {
+	if i.Instance == "" {
+		return name
+	}
+	return fmt.Sprintf("%s_%s", i.Instance, name)
+}
+
+func (i *InstanceAware) selenoidConfigDirElem() []string {
+	return getConfigDirElem(i.Instance, selenoidConfigDirElem)
+}
+
 const (
 	DefaultPort           = 4444
 	UIDefaultPort         = 8080
@@ -156,12 +208,60 @@ func joinPaths(baseDir string, elem []string) string {
 var (
 	selenoidConfigDirElem   = []string{".aerokube", "selenoid"}
 	selenoidUIConfigDirElem = []string{".aerokube", "selenoid-ui"}
+	instancesDirElem        = []string{".aerokube", "instances"}
+	cacheDirElem            = []string{".aerokube", "cache"}
+	instanceNameRegexp      = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
 )
 
 func GetSelenoidConfigDir() string {
-	return joinPaths(getHomeDir(), selenoidConfigDirElem)
+	return GetSelenoidInstanceConfigDir("")
 }
 
 func GetSelenoidUIConfigDir() string {
-	return joinPaths(getHomeDir(), selenoidUIConfigDirElem)
+	return GetSelenoidUIInstanceConfigDir("")
+}
+
+// GetCacheDir returns default directory to cache downloaded drivers and binaries
+func GetCacheDir() string {
+	return joinPaths(getHomeDir(), cacheDirElem)
+}
+
+// GetSelenoidInstanceConfigDir returns default configuration directory of named Selenoid instance
+func GetSelenoidInstanceConfigDir(instance string) string {
+	return joinPaths(getHomeDir(), getConfigDirElem(instance, selenoidConfigDirElem))
+}
+
+// GetSelenoidUIInstanceConfigDir returns default configuration directory of named Selenoid UI instance
+func GetSelenoidUIInstanceConfigDir(instance string) string {
+	return joinPaths(getHomeDir(), getConfigDirElem(instance, selenoidUIConfigDirElem))
+}
+
+func getConfigDirElem(instance string, defaultElem []string) []string {
+	if instance == "" {
+		return defaultElem
+	}
+	elem := append([]string{}, instancesDirElem...)
+	return append(elem, instance, defaultElem[len(defaultElem)-1])
+}
+
+func validateInstanceName(instance string) error {
+	if instance != "" && !instanceNameRegexp.MatchString(instance) {
+		return fmt.Errorf("invalid instance name %s: only letters, digits, underscores, dots and dashes are allowed", instance)
+	}
+	return nil
+}
+
+// listInstances returns names of all named instances having configuration directories
+func listInstances() []string {
+	var ret []string
+	entries, err := os.ReadDir(joinPaths(getHomeDir(), instancesDirElem))
+	if err != nil {
+		return ret
+	}
+	for _, e := range entries {
+		if e.IsDir() && validateInstanceName(e.Name()) == nil {
+			ret = append(ret, e.Name())
+		}
+	}
+	return ret
 }
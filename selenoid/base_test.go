@@ -2,6 +2,7 @@ package selenoid
 
 import (
 	"path/filepath"
+	"strings"
 	"testing"
 
 	assert "github.com/stretchr/testify/require"
@@ -15,3 +16,22 @@ func TestGetConfigDir(t *testing.T) {
 	assert.NotEmpty(t, selenoidUIConfigDir)
 	assert.True(t, filepath.IsAbs(selenoidUIConfigDir))
 }
+
+func TestInstanceConfigDir(t *testing.T) {
+	assert.Equal(t, GetSelenoidConfigDir(), GetSelenoidInstanceConfigDir(""))
+	instanceConfigDir := GetSelenoidInstanceConfigDir("team-a")
+	assert.True(t, strings.HasSuffix(instanceConfigDir, filepath.Join(".aerokube", "instances", "team-a", "selenoid")))
+	uiInstanceConfigDir := GetSelenoidUIInstanceConfigDir("team-a")
+	assert.True(t, strings.HasSuffix(uiInstanceConfigDir, filepath.Join(".aerokube", "instances", "team-a", "selenoid-ui")))
+}
+
+func TestInstanceName(t *testing.T) {
+	assert.Equal(t, "selenoid", (&InstanceAware{}).instanceName("selenoid"))
+	assert.Equal(t, "team-a_selenoid", (&InstanceAware{Instance: "team-a"}).instanceName("selenoid"))
+	assert.NotEqual(t, "selenoid-ui", (&InstanceAware{Instance: "ui"}).instanceName("selenoid"))
+	assert.NotEqual(t, (&InstanceAware{Instance: "x"}).instanceName("selenoid-ui"), (&InstanceAware{Instance: "ui-x"}).instanceName("selenoid"))
+	assert.NoError(t, validateInstanceName(""))
+	assert.NoError(t, validateInstanceName("team_a.1"))
+	assert.Error(t, validateInstanceName("../team"))
+	assert.Error(t, validateInstanceName("-team"))
+}
@@ -0,0 +1,266 @@
+package selenoid
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types/image"
+	"github.com/fatih/color"
+)
+
+const (
+	bundleManifestFileName = "manifest.json"
+	bundleConfigFileName   = "browsers.json"
+	bundleImagesFileName   = "images.tar"
+)
+
+// bundleManifest describes images saved to offline bundle
+type bundleManifest struct {
+	SelenoidImage      string   `json:"selenoidImage"`
+	UIImage            string   `json:"uiImage,omitempty"`
+	VideoRecorderImage string   `json:"videoRecorderImage,omitempty"`
+	BrowserImages      []string `json:"browserImages"`
+}
+
+func (m *bundleManifest) images() []string {
+	ret := []string{m.SelenoidImage}
+	for _, ref := range []string{m.UIImage, m.VideoRecorderImage} {
+		if ref != "" {
+			ret = append(ret, ref)
+		}
+	}
+	return append(ret, m.BrowserImages...)
+}
+
+// ExportBundle saves Selenoid, Selenoid UI, video recorder and browser images together with browsers.json to a single archive
+func (c *DockerConfigurator) ExportBundle(ctx context.Context, path string) error {
+	configPath := getSelenoidConfigPath(c.ConfigDir)
+	configData, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to read browsers.json from %s: configure Selenoid first: %v", configPath, err)
+	}
+	var cfg SelenoidConfig
+	err = json.Unmarshal(configData, &cfg)
+	if err != nil {
+		return fmt.Errorf("failed to parse browsers.json from %s: %v", configPath, err)
+	}
+	manifest, err := c.createBundleManifest(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundle manifest: %v", err)
+	}
+
+	// Tar header requires entry size, so images are saved to a temporary file first
+	c.Pointf("Saving %d images...", len(manifest.images()))
+	images, err := os.CreateTemp("", "cm-bundle-*.tar")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %v", err)
+	}
+	defer os.Remove(images.Name())
+	defer images.Close()
+	r, err := c.docker.ImageSave(ctx, manifest.images())
+	if err != nil {
+		return fmt.Errorf("failed to save images: %v", err)
+	}
+	defer r.Close()
+	size, err := io.Copy(images, r)
+	if err != nil {
+		return fmt.Errorf("failed to save images: %v", err)
+	}
+	_, err = images.Seek(0, io.SeekStart)
+	if err != nil {
+		return fmt.Errorf("failed to read saved images: %v", err)
+	}
+
+	err = ctx.Err()
+	if err != nil {
+		return err
+	}
+	return replaceFile(path, 0644, func(w io.Writer) error {
+		tw := tar.NewWriter(w)
+		for _, entry := range []struct {
+			name string
+			data []byte
+		}{
+			{bundleManifestFileName, manifestData},
+			{bundleConfigFileName, configData},
+		} {
+			if err := writeTarEntry(tw, entry.name, int64(len(entry.data)), bytes.NewReader(entry.data)); err != nil {
+				return err
+			}
+		}
+		if err := writeTarEntry(tw, bundleImagesFileName, size, images); err != nil {
+			return err
+		}
+		return tw.Close()
+	})
+}
+
+func writeTarEntry(tw *tar.Writer, name string, size int64, r io.Reader) error {
+	err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: size})
+	if err != nil {
+		return fmt.Errorf("failed to write %s to bundle: %v", name, err)
+	}
+	_, err = io.Copy(tw, r)
+	if err != nil {
+		return fmt.Errorf("failed to write %s to bundle: %v", name, err)
+	}
+	return nil
+}
+
+func (c *DockerConfigurator) createBundleManifest(ctx context.Context, cfg SelenoidConfig) (*bundleManifest, error) {
+	selenoidImg := c.getSelenoidImage()
+	if selenoidImg == nil {
+		return nil, errors.New("selenoid image is not downloaded: download it first")
+	}
+	manifest := &bundleManifest{SelenoidImage: selenoidImg.RepoTags[0]}
+	if uiImg := c.getImage(selenoidUIImage, Latest); uiImg != nil {
+		manifest.UIImage = uiImg.RepoTags[0]
+	} else {
+		c.Errorf("Selenoid UI image is not downloaded, skipping it")
+	}
+
+	localImages, err := c.docker.ImageList(ctx, image.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %v", err)
+	}
+	tags := make(map[string]struct{})
+	for _, img := range localImages {
+		for _, tag := range img.RepoTags {
+			tags[normalizeImageRef(tag)] = struct{}{}
+		}
+	}
+	videoRecorder := c.selenoidRegistry.imageRef(videoRecorderImage)
+	if _, ok := tags[normalizeImageRef(videoRecorder)]; ok {
+		manifest.VideoRecorderImage = videoRecorder
+	} else {
+		c.Errorf("Video recorder image is not downloaded, skipping it")
+	}
+
+	browserImages := make(map[string]struct{})
+	var missing []string
+	for _, versions := range cfg {
+		for _, version := range versions.Versions {
+			ref, ok := version.Image.(string)
+			if !ok {
+				c.Pointf("Skipping non-Docker image specification: %v", version.Image)
+				continue
+			}
+			if _, ok := tags[normalizeImageRef(ref)]; !ok {
+				missing = append(missing, ref)
+				continue
+			}
+			browserImages[ref] = struct{}{}
+		}
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("browser images are not downloaded: %s", strings.Join(missing, ", "))
+	}
+	for ref := range browserImages {
+		manifest.BrowserImages = append(manifest.BrowserImages, ref)
+	}
+	sort.Strings(manifest.BrowserImages)
+	return manifest, nil
+}
+
+// normalizeImageRef makes references to default registry and implicit latest tag comparable
+func normalizeImageRef(ref string) string {
+	ref = strings.TrimPrefix(ref, "docker.io/")
+	ref = strings.TrimPrefix(ref, "library/")
+	if strings.LastIndex(ref, ":") <= strings.LastIndex(ref, "/") {
+		ref += ":latest"
+	}
+	return ref
+}
+
+// ImportBundle loads images from offline bundle and saves its browsers.json to configuration directory without accessing registry
+func (c *DockerConfigurator) ImportBundle(ctx context.Context, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open bundle: %v", err)
+	}
+	defer f.Close()
+	err = c.createConfigDir()
+	if err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	var manifest *bundleManifest
+	var configData []byte
+	imagesLoaded := false
+	tr := tar.NewReader(f)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read bundle: %v", err)
+		}
+		switch header.Name {
+		case bundleManifestFileName:
+			manifest = &bundleManifest{}
+			err = json.NewDecoder(tr).Decode(manifest)
+			if err != nil {
+				return fmt.Errorf("failed to parse bundle manifest: %v", err)
+			}
+		case bundleConfigFileName:
+			configData, err = io.ReadAll(tr)
+			if err != nil {
+				return fmt.Errorf("failed to read browsers.json from bundle: %v", err)
+			}
+		case bundleImagesFileName:
+			if manifest != nil {
+				c.Pointf("Loading %d images...", len(manifest.images()))
+			}
+			resp, err := c.docker.ImageLoad(ctx, tr, true)
+			if err != nil {
+				return fmt.Errorf("failed to load images: %v", err)
+			}
+			err = readImageLoadResponse(resp.Body)
+			if err != nil {
+				return fmt.Errorf("failed to load images: %v", err)
+			}
+			imagesLoaded = true
+		}
+	}
+	if manifest == nil || configData == nil || !imagesLoaded {
+		return fmt.Errorf("%s is not a valid Selenoid bundle", path)
+	}
+	for _, ref := range manifest.images() {
+		c.Pointf("Loaded image %v", color.BlueString(ref))
+	}
+	return writeSelenoidConfig(ctx, c.ConfigDir, configData)
+}
+
+func readImageLoadResponse(body io.ReadCloser) error {
+	defer body.Close()
+	dec := json.NewDecoder(body)
+	for {
+		var msg struct {
+			Error string `json:"error"`
+		}
+		err := dec.Decode(&msg)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Error != "" {
+			return errors.New(msg.Error)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package selenoid
+
+import (
+	"archive/tar"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestExportImportBundle(t *testing.T) {
+	withTmpDir(t, "bundle", func(t *testing.T, dir string) {
+		exportDir := filepath.Join(dir, "export")
+		assert.NoError(t, os.MkdirAll(exportDir, os.ModePerm))
+		browsersJson := []byte(`{"firefox": {"default": "latest", "versions": {"latest": {"image": "aerokube/selenoid", "port": "4444"}}}}`)
+		assert.NoError(t, os.WriteFile(getSelenoidConfigPath(exportDir), browsersJson, 0644))
+
+		c, err := NewDockerConfigurator(&LifecycleConfig{
+			ConfigDir:   exportDir,
+			RegistryUrl: mockDockerServer.URL,
+			Version:     Latest,
+			Quiet:       true,
+		})
+		assert.NoError(t, err)
+		defer c.Close()
+		bundlePath := filepath.Join(dir, "selenoid.tar")
+		assert.NoError(t, c.ExportBundle(context.Background(), bundlePath))
+
+		entries := readTarEntries(t, bundlePath)
+		assert.Equal(t, browsersJson, entries[bundleConfigFileName])
+		assert.Equal(t, "saved:docker.io/aerokube/selenoid:latest,aerokube/selenoid", string(entries[bundleImagesFileName]))
+		var manifest bundleManifest
+		assert.NoError(t, json.Unmarshal(entries[bundleManifestFileName], &manifest))
+		assert.Equal(t, "docker.io/aerokube/selenoid:latest", manifest.SelenoidImage)
+		assert.Equal(t, []string{"aerokube/selenoid"}, manifest.BrowserImages)
+
+		importDir := filepath.Join(dir, "import")
+		c.ConfigDir = importDir
+		assert.NoError(t, c.ImportBundle(context.Background(), bundlePath))
+		data, err := os.ReadFile(getSelenoidConfigPath(importDir))
+		assert.NoError(t, err)
+		assert.Equal(t, browsersJson, data)
+
+		assert.Error(t, c.ImportBundle(context.Background(), getSelenoidConfigPath(importDir)))
+	})
+}
+
+func TestExportBundleMissingImages(t *testing.T) {
+	withTmpDir(t, "bundle-missing", func(t *testing.T, dir string) {
+		browsersJson := []byte(`{"opera": {"default": "106.0", "versions": {"106.0": {"image": "selenoid/opera:106.0", "port": "4444"}}}}`)
+		assert.NoError(t, os.WriteFile(getSelenoidConfigPath(dir), browsersJson, 0644))
+		c, err := NewDockerConfigurator(&LifecycleConfig{
+			ConfigDir:   dir,
+			RegistryUrl: mockDockerServer.URL,
+			Version:     Latest,
+			Quiet:       true,
+		})
+		assert.NoError(t, err)
+		defer c.Close()
+		bundlePath := filepath.Join(dir, "selenoid.tar")
+		err = c.ExportBundle(context.Background(), bundlePath)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "selenoid/opera:106.0")
+		assert.False(t, fileExists(bundlePath))
+	})
+}
+
+func TestNormalizeImageRef(t *testing.T) {
+	assert.Equal(t, "selenoid/chrome:latest", normalizeImageRef("docker.io/selenoid/chrome"))
+	assert.Equal(t, "ubuntu:latest", normalizeImageRef("docker.io/library/ubuntu"))
+	assert.Equal(t, "localhost:5000/selenoid/chrome:120.0", normalizeImageRef("localhost:5000/selenoid/chrome:120.0"))
+	assert.Equal(t, "localhost:5000/selenoid/chrome:latest", normalizeImageRef("localhost:5000/selenoid/chrome"))
+}
+
+func readTarEntries(t *testing.T, path string) map[string][]byte {
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close()
+	ret := make(map[string][]byte)
+	tr := tar.NewReader(f)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return ret
+		}
+		assert.NoError(t, err)
+		data, err := io.ReadAll(tr)
+		assert.NoError(t, err)
+		ret[header.Name] = data
+	}
+}
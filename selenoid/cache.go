@@ -0,0 +1,334 @@
+package selenoid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+const (
+	cacheMetadataSuffix = ".json"
+	cachePartialSuffix  = ".part"
+)
+
+// CacheEntry describes a file downloaded to cache directory
+type CacheEntry struct {
+	URL          string    `json:"url"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	Size         int64     `json:"size"`
+	Complete     bool      `json:"complete"`
+	Updated      time.Time `json:"updated"`
+}
+
+// validator returns a value allowing to check that remote file was not changed since it was cached
+func (e *CacheEntry) validator() string {
+	if e.ETag != "" {
+		return e.ETag
+	}
+	return e.LastModified
+}
+
+// Cache stores downloaded driver archives and release binaries keyed by URL
+type Cache struct {
+	Logger
+	Dir    string
+	Client *http.Client
+}
+
+func NewCache(dir string, quiet bool) *Cache {
+	return &Cache{Logger: Logger{Quiet: quiet}, Dir: dir}
+}
+
+func (c *Cache) getDataPath(url string) string {
+	return filepath.Join(c.Dir, sha256Sum([]byte(url)))
+}
+
+func (c *Cache) readEntry(url string) *CacheEntry {
+	data, err := os.ReadFile(c.getDataPath(url) + cacheMetadataSuffix)
+	if err != nil {
+		return nil
+	}
+	var entry CacheEntry
+	if json.Unmarshal(data, &entry) != nil || entry.URL != url {
+		return nil
+	}
+	return &entry
+}
+
+func (c *Cache) writeEntry(entry *CacheEntry) error {
+	data, err := json.MarshalIndent(entry, "", "    ")
+	if err != nil {
+		return err
+	}
+	return replaceFile(c.getDataPath(entry.URL)+cacheMetadataSuffix, 0644, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+func (c *Cache) remove(url string) {
+	dataPath := c.getDataPath(url)
+	for _, p := range []string{dataPath, dataPath + cachePartialSuffix, dataPath + cacheMetadataSuffix} {
+		_ = os.Remove(p)
+	}
+}
+
+// fetch returns path to cached copy of remote file: complete copies are revalidated with ETag or Last-Modified
+// and partial ones are resumed with HTTP Range requests
+func (c *Cache) fetch(ctx context.Context, url string) (string, error) {
+	err := os.MkdirAll(c.Dir, os.ModePerm)
+	if err != nil {
+		return "", fmt.Errorf("failed to create cache directory: %v", err)
+	}
+	dataPath := c.getDataPath(url)
+	partPath := dataPath + cachePartialSuffix
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("file download error: %v", err)
+	}
+
+	cached := false
+	var offset int64
+	entry := c.readEntry(url)
+	if entry != nil && entry.Complete && fileExists(dataPath) {
+		cached = true
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		} else if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		} else {
+			c.Pointf("Using cached copy of %s", color.BlueString(url))
+			return dataPath, nil
+		}
+	} else if entry != nil && entry.validator() != "" {
+		if fi, err := os.Stat(partPath); err == nil && fi.Size() > 0 {
+			offset = fi.Size()
+			req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+			req.Header.Set("If-Range", entry.validator())
+		}
+	}
+
+	client := c.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		if cached && ctx.Err() == nil {
+			c.Pointf("Using cached copy of %s: %v", color.BlueString(url), err)
+			return dataPath, nil
+		}
+		return "", fmt.Errorf("file download error: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached:
+		c.Pointf("Using cached copy of %s", color.BlueString(url))
+		return dataPath, nil
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		if !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)) {
+			c.remove(url)
+			return "", fmt.Errorf("unexpected content range: %s", resp.Header.Get("Content-Range"))
+		}
+		c.Pointf("Resuming download from byte %d", offset)
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable && offset > 0:
+		return c.finishPartial(ctx, entry, offset, resp.Header.Get("Content-Range"))
+	case resp.StatusCode == http.StatusOK:
+		offset = 0
+	default:
+		return "", unexpectedStatusError(resp.StatusCode)
+	}
+
+	newEntry := &CacheEntry{
+		URL:          url,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Updated:      time.Now(),
+	}
+	if offset > 0 && newEntry.validator() == "" {
+		newEntry.ETag, newEntry.LastModified = entry.ETag, entry.LastModified
+	}
+	// Validators are saved before transfer so that interrupted download can be resumed
+	err = c.writeEntry(newEntry)
+	if err != nil {
+		return "", fmt.Errorf("failed to save cache metadata: %v", err)
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if offset > 0 {
+		flags = os.O_WRONLY | os.O_APPEND
+	}
+	f, err := os.OpenFile(partPath, flags, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to save file: %v", err)
+	}
+	total := int64(-1)
+	if resp.ContentLength >= 0 {
+		total = offset + resp.ContentLength
+	}
+	writer, finish := withProgressBar(f, total, offset)
+	size, err := io.Copy(writer, resp.Body)
+	finish()
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to save file: %v", err)
+	}
+	if total >= 0 && offset+size != total {
+		return "", fmt.Errorf("failed to save file: expected %d bytes but got %d", total, offset+size)
+	}
+
+	err = os.Rename(partPath, dataPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to save file: %v", err)
+	}
+	newEntry.Size = offset + size
+	newEntry.Complete = true
+	err = c.writeEntry(newEntry)
+	if err != nil {
+		return "", fmt.Errorf("failed to save cache metadata: %v", err)
+	}
+	return dataPath, nil
+}
+
+// finishPartial handles rejected Range request: partial copy having remote file size is already complete,
+// otherwise it is removed and file is downloaded from scratch
+func (c *Cache) finishPartial(ctx context.Context, entry *CacheEntry, offset int64, contentRange string) (string, error) {
+	dataPath := c.getDataPath(entry.URL)
+	if size, ok := parseUnsatisfiedRange(contentRange); ok && size == offset {
+		err := os.Rename(dataPath+cachePartialSuffix, dataPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to save file: %v", err)
+		}
+		entry.Size = size
+		entry.Complete = true
+		entry.Updated = time.Now()
+		err = c.writeEntry(entry)
+		if err != nil {
+			return "", fmt.Errorf("failed to save cache metadata: %v", err)
+		}
+		return dataPath, nil
+	}
+	c.Pointf("Cached part of %s is invalid, downloading it again", color.BlueString(entry.URL))
+	c.remove(entry.URL)
+	return c.fetch(ctx, entry.URL)
+}
+
+// parseUnsatisfiedRange returns complete file size from Content-Range header of 416 response, e.g. bytes */1234
+func parseUnsatisfiedRange(contentRange string) (int64, bool) {
+	size, ok := strings.CutPrefix(contentRange, "bytes */")
+	if !ok {
+		return 0, false
+	}
+	ret, err := strconv.ParseInt(size, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return ret, true
+}
+
+// Entries returns all cached files sorted by URL
+func (c *Cache) Entries() ([]*CacheEntry, error) {
+	files, err := os.ReadDir(c.Dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory: %v", err)
+	}
+	var ret []*CacheEntry
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), cacheMetadataSuffix) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(c.Dir, f.Name()))
+		if err != nil {
+			continue
+		}
+		var entry CacheEntry
+		if json.Unmarshal(data, &entry) != nil || entry.URL == "" {
+			continue
+		}
+		if !entry.Complete {
+			if fi, err := os.Stat(c.getDataPath(entry.URL) + cachePartialSuffix); err == nil {
+				entry.Size = fi.Size()
+			}
+		}
+		ret = append(ret, &entry)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].URL < ret[j].URL
+	})
+	return ret, nil
+}
+
+// List prints cached files
+func (c *Cache) List(w io.Writer) error {
+	entries, err := c.Entries()
+	if err != nil {
+		return err
+	}
+	if len(entries) == 0 {
+		c.Titlef("Cache directory %s is empty", color.BlueString(c.Dir))
+		return nil
+	}
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "URL\tSIZE\tSTATUS\tUPDATED")
+	var total int64
+	for _, e := range entries {
+		status := "complete"
+		if !e.Complete {
+			status = "partial"
+		}
+		total += e.Size
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", e.URL, formatSize(e.Size), status, e.Updated.Format(time.RFC3339))
+	}
+	err = tw.Flush()
+	if err != nil {
+		return err
+	}
+	c.Titlef("%d files, %s in %s", len(entries), formatSize(total), color.BlueString(c.Dir))
+	return nil
+}
+
+// Clean removes all cached files
+func (c *Cache) Clean() error {
+	entries, err := c.Entries()
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, e := range entries {
+		c.remove(e.URL)
+		total += e.Size
+	}
+	c.Titlef("Removed %d files (%s) from %s", len(entries), formatSize(total), color.BlueString(c.Dir))
+	return nil
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,180 @@
+package selenoid
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+const cachedFileContents = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+type cacheTestServer struct {
+	*httptest.Server
+	etag     string
+	requests []*http.Request
+	statuses []int
+}
+
+func newCacheTestServer() *cacheTestServer {
+	s := &cacheTestServer{etag: `"v1"`}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests = append(s.requests, r)
+		rw := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		rw.Header().Set("ETag", s.etag)
+		http.ServeContent(rw, r, "file", time.Time{}, strings.NewReader(cachedFileContents))
+		s.statuses = append(s.statuses, rw.status)
+	}))
+	return s
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func TestCacheFetch(t *testing.T) {
+	srv := newCacheTestServer()
+	defer srv.Close()
+	withTmpDir(t, "test-cache", func(t *testing.T, dir string) {
+		cache := NewCache(dir, true)
+		url := srv.URL + "/file"
+		path, err := cache.fetch(context.Background(), url)
+		assert.NoError(t, err)
+		checkContentsEqual(t, path, cachedFileContents)
+		assert.Equal(t, http.StatusOK, srv.statuses[0])
+
+		path, err = cache.fetch(context.Background(), url)
+		assert.NoError(t, err)
+		checkContentsEqual(t, path, cachedFileContents)
+		assert.Equal(t, `"v1"`, srv.requests[1].Header.Get("If-None-Match"))
+		assert.Equal(t, http.StatusNotModified, srv.statuses[1])
+
+		entries, err := cache.Entries()
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+		assert.Equal(t, url, entries[0].URL)
+		assert.Equal(t, `"v1"`, entries[0].ETag)
+		assert.Equal(t, int64(len(cachedFileContents)), entries[0].Size)
+		assert.True(t, entries[0].Complete)
+
+		var buf bytes.Buffer
+		assert.NoError(t, cache.List(&buf))
+		assert.Contains(t, buf.String(), url)
+
+		assert.NoError(t, cache.Clean())
+		entries, err = cache.Entries()
+		assert.NoError(t, err)
+		assert.Empty(t, entries)
+		assert.False(t, fileExists(path))
+	})
+}
+
+func TestCacheResume(t *testing.T) {
+	srv := newCacheTestServer()
+	defer srv.Close()
+	withTmpDir(t, "test-cache", func(t *testing.T, dir string) {
+		cache := NewCache(dir, true)
+		url := srv.URL + "/file"
+		savePartialDownload(t, cache, url, `"v1"`, 10)
+
+		entries, err := cache.Entries()
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+		assert.False(t, entries[0].Complete)
+		assert.Equal(t, int64(10), entries[0].Size)
+
+		path, err := cache.fetch(context.Background(), url)
+		assert.NoError(t, err)
+		checkContentsEqual(t, path, cachedFileContents)
+		assert.Equal(t, "bytes=10-", srv.requests[0].Header.Get("Range"))
+		assert.Equal(t, http.StatusPartialContent, srv.statuses[0])
+		assert.False(t, fileExists(path+cachePartialSuffix))
+	})
+}
+
+func TestCacheResumeChangedFile(t *testing.T) {
+	srv := newCacheTestServer()
+	defer srv.Close()
+	withTmpDir(t, "test-cache", func(t *testing.T, dir string) {
+		cache := NewCache(dir, true)
+		url := srv.URL + "/file"
+		savePartialDownload(t, cache, url, `"v0"`, 10)
+
+		path, err := cache.fetch(context.Background(), url)
+		assert.NoError(t, err)
+		checkContentsEqual(t, path, cachedFileContents)
+		assert.Equal(t, http.StatusOK, srv.statuses[0])
+		assert.Equal(t, `"v1"`, cache.readEntry(url).ETag)
+	})
+}
+
+func TestCacheResumeCompleteFile(t *testing.T) {
+	srv := newCacheTestServer()
+	defer srv.Close()
+	withTmpDir(t, "test-cache", func(t *testing.T, dir string) {
+		cache := NewCache(dir, true)
+		url := srv.URL + "/file"
+		savePartialDownload(t, cache, url, `"v1"`, len(cachedFileContents))
+
+		path, err := cache.fetch(context.Background(), url)
+		assert.NoError(t, err)
+		checkContentsEqual(t, path, cachedFileContents)
+		assert.Len(t, srv.requests, 1)
+		assert.Equal(t, http.StatusRequestedRangeNotSatisfiable, srv.statuses[0])
+		assert.False(t, fileExists(path+cachePartialSuffix))
+		entry := cache.readEntry(url)
+		assert.True(t, entry.Complete)
+		assert.Equal(t, int64(len(cachedFileContents)), entry.Size)
+	})
+}
+
+func TestCacheResumeInvalidPart(t *testing.T) {
+	srv := newCacheTestServer()
+	defer srv.Close()
+	withTmpDir(t, "test-cache", func(t *testing.T, dir string) {
+		cache := NewCache(dir, true)
+		url := srv.URL + "/file"
+		savePartialDownload(t, cache, url, `"v1"`, 0)
+		assert.NoError(t, os.WriteFile(cache.getDataPath(url)+cachePartialSuffix, []byte(cachedFileContents+"garbage"), 0644))
+
+		path, err := cache.fetch(context.Background(), url)
+		assert.NoError(t, err)
+		checkContentsEqual(t, path, cachedFileContents)
+		assert.Equal(t, []int{http.StatusRequestedRangeNotSatisfiable, http.StatusOK}, srv.statuses)
+		assert.Empty(t, srv.requests[1].Header.Get("Range"))
+		assert.False(t, fileExists(path+cachePartialSuffix))
+	})
+}
+
+func TestCacheFetchError(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	withTmpDir(t, "test-cache", func(t *testing.T, dir string) {
+		_, err := NewCache(dir, true).fetch(context.Background(), srv.URL+"/missing")
+		assert.Error(t, err)
+	})
+}
+
+func savePartialDownload(t *testing.T, cache *Cache, url string, etag string, size int) {
+	assert.NoError(t, os.MkdirAll(cache.Dir, os.ModePerm))
+	assert.NoError(t, cache.writeEntry(&CacheEntry{URL: url, ETag: etag}))
+	assert.NoError(t, os.WriteFile(cache.getDataPath(url)+cachePartialSuffix, []byte(cachedFileContents[:size]), 0644))
+}
+
+func TestFormatSize(t *testing.T) {
+	assert.Equal(t, "512 B", formatSize(512))
+	assert.Equal(t, "1.5 KiB", formatSize(1536))
+	assert.Equal(t, "2.0 MiB", formatSize(2*1024*1024))
+}
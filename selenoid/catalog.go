@@ -0,0 +1,176 @@
+package selenoid
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+)
+
+const (
+	defaultBrowserPort = "4444"
+	defaultBrowserPath = "/"
+	wdHubPath          = "/wd/hub"
+
+	ProtocolWebDriver  = "webdriver"
+	ProtocolPlaywright = "playwright"
+
+	// protocolLabel marks browsers.json entries of non-WebDriver browsers
+	protocolLabel = "com.aerokube.cm.protocol"
+
+	playwrightChromium = "playwright-chromium"
+	playwrightFirefox  = "playwright-firefox"
+	playwrightWebkit   = "playwright-webkit"
+
+	// Playwright images run browser server accepting connections on this port and path
+	playwrightPort = "3000"
+	playwrightPath = "/"
+)
+
+// ImageCatalog maps browser names to images used to configure them in Docker mode
+type ImageCatalog map[string]*CatalogEntry
+
+// CatalogEntry describes browser image repository and defaults of browsers.json entries created for its tags
+type CatalogEntry struct {
+	// Image repository without tag, e.g. selenoid/chrome or registry.example.com/team/chrome.
+	// Images without registry host are taken from browsers registry.
+	Image string `json:"image"`
+	// Port and Path of WebDriver (or other protocol) endpoint inside container, 4444 and / by default
+	Port string `json:"port,omitempty"`
+	Path string `json:"path,omitempty"`
+	// Paths overrides Path for specific tags
+	Paths map[string]string `json:"paths,omitempty"`
+	// TagFilter is a regular expression tags should match to be configured, e.g. ^[0-9.]+$ to skip beta images
+	TagFilter string `json:"tagFilter,omitempty"`
+	// Protocol is either webdriver (default) or playwright
+	Protocol string `json:"protocol,omitempty"`
+	// Default entries are configured when no browsers are requested explicitly
+	Default bool `json:"default,omitempty"`
+	// Env, ShmSize and Tmpfs (both in megabytes) are used unless --browser-env, --shm-size or --tmpfs flags are specified
+	Env     []string `json:"env,omitempty"`
+	ShmSize int      `json:"shmSize,omitempty"`
+	Tmpfs   int      `json:"tmpfs,omitempty"`
+
+	tagFilter *regexp.Regexp
+}
+
+// builtInCatalog returns images supported out of the box
+func builtInCatalog() ImageCatalog {
+	return ImageCatalog{
+		firefox:  {Image: "selenoid/firefox", Path: wdHubPath, Default: true},
+		"chrome": {Image: "selenoid/chrome", Default: true},
+		opera:    {Image: "selenoid/opera", Paths: map[string]string{tag_1216: wdHubPath}, Default: true},
+		android:  {Image: "selenoid/android", Path: wdHubPath},
+		edge:     {Image: "browsers/edge"},
+
+		playwrightChromium: {Image: "browsers/playwright-chromium", Port: playwrightPort, Path: playwrightPath, Protocol: ProtocolPlaywright},
+		playwrightFirefox:  {Image: "browsers/playwright-firefox", Port: playwrightPort, Path: playwrightPath, Protocol: ProtocolPlaywright},
+		playwrightWebkit:   {Image: "browsers/playwright-webkit", Port: playwrightPort, Path: playwrightPath, Protocol: ProtocolPlaywright},
+	}
+}
+
+// loadImageCatalog returns built-in catalog with entries from file added or replaced by browser name
+func loadImageCatalog(path string) (ImageCatalog, error) {
+	catalog := builtInCatalog()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read images catalog: %v", err)
+		}
+		var custom ImageCatalog
+		err = json.Unmarshal(data, &custom)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse images catalog %s: %v", path, err)
+		}
+		for browserName, entry := range custom {
+			if entry == nil {
+				delete(catalog, browserName)
+				continue
+			}
+			catalog[browserName] = entry
+		}
+	}
+	for _, browserName := range catalog.browserNames() {
+		err := catalog[browserName].init()
+		if err != nil {
+			return nil, fmt.Errorf("invalid images catalog entry %s: %v", browserName, err)
+		}
+	}
+	return catalog, nil
+}
+
+func (e *CatalogEntry) init() error {
+	if e.Image == "" {
+		return errors.New("image is not specified")
+	}
+	switch e.Protocol {
+	case "", ProtocolWebDriver, ProtocolPlaywright:
+	default:
+		return fmt.Errorf("unsupported protocol: %s", e.Protocol)
+	}
+	if e.TagFilter != "" {
+		re, err := regexp.Compile(e.TagFilter)
+		if err != nil {
+			return fmt.Errorf("invalid tag filter: %v", err)
+		}
+		e.tagFilter = re
+	}
+	return nil
+}
+
+func (catalog ImageCatalog) browserNames() []string {
+	var ret []string
+	for browserName := range catalog {
+		ret = append(ret, browserName)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// matchingTags returns tags matching tag filter keeping their order
+func (e *CatalogEntry) matchingTags(tags []string) []string {
+	if e.tagFilter == nil {
+		return tags
+	}
+	var ret []string
+	for _, tag := range tags {
+		if e.tagFilter.MatchString(tag) {
+			ret = append(ret, tag)
+		}
+	}
+	return ret
+}
+
+func (e *CatalogEntry) getProtocol() string {
+	if e.Protocol != "" {
+		return e.Protocol
+	}
+	return ProtocolWebDriver
+}
+
+// getLabels returns labels distinguishing browsers.json entries of Playwright browsers from WebDriver ones
+func (e *CatalogEntry) getLabels() map[string]string {
+	if e.getProtocol() == ProtocolWebDriver {
+		return nil
+	}
+	return map[string]string{protocolLabel: e.getProtocol()}
+}
+
+func (e *CatalogEntry) getPort() string {
+	if e.Port != "" {
+		return e.Port
+	}
+	return defaultBrowserPort
+}
+
+func (e *CatalogEntry) getPath(tag string) string {
+	if path, ok := e.Paths[tag]; ok {
+		return path
+	}
+	if e.Path != "" {
+		return e.Path
+	}
+	return defaultBrowserPath
+}
@@ -0,0 +1,183 @@
+package selenoid
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aerokube/selenoid/config"
+	configtypes "github.com/docker/cli/cli/config/types"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestBuiltInCatalog(t *testing.T) {
+	catalog, err := loadImageCatalog("")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"MicrosoftEdge", "android", "chrome", "firefox", "opera", "playwright-chromium", "playwright-firefox", "playwright-webkit"}, catalog.browserNames())
+	assert.Equal(t, wdHubPath, catalog[opera].getPath(tag_1216))
+	assert.Equal(t, defaultBrowserPath, catalog[opera].getPath("44.0"))
+	assert.Equal(t, defaultBrowserPort, catalog[firefox].getPort())
+	assert.Nil(t, catalog[firefox].getLabels())
+	assert.Equal(t, map[string]string{protocolLabel: ProtocolPlaywright}, catalog[playwrightChromium].getLabels())
+}
+
+func TestLoadImageCatalog(t *testing.T) {
+	withTmpDir(t, "test-catalog", func(t *testing.T, dir string) {
+		catalogFile := filepath.Join(dir, "catalog.json")
+		data := `{
+			"firefox": {"image": "example/firefox", "tagFilter": "^[0-9.]+$", "default": true},
+			"hardened-chrome": {"image": "example/chrome", "port": "9515", "path": "/wd/hub", "shmSize": 512, "env": ["LANG=en_US.UTF-8"]},
+			"opera": null
+		}`
+		assert.NoError(t, os.WriteFile(catalogFile, []byte(data), 0644))
+		catalog, err := loadImageCatalog(catalogFile)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"MicrosoftEdge", "android", "chrome", "firefox", "hardened-chrome", "playwright-chromium", "playwright-firefox", "playwright-webkit"}, catalog.browserNames())
+		assert.Equal(t, "example/firefox", catalog[firefox].Image)
+		assert.Equal(t, []string{"120.0", "119.0"}, catalog[firefox].matchingTags([]string{"121.0-beta", "120.0", "119.0"}))
+		assert.Equal(t, "9515", catalog["hardened-chrome"].getPort())
+
+		for _, invalid := range []string{`{"firefox": {"tagFilter": "[0-9"}}`, `{"firefox": {"image": "example/firefox", "tagFilter": "[0-9"}}`, `{"firefox": {"image": "example/firefox", "protocol": "cdp"}}`, `not json`} {
+			assert.NoError(t, os.WriteFile(catalogFile, []byte(invalid), 0644))
+			_, err = loadImageCatalog(catalogFile)
+			assert.Error(t, err, invalid)
+		}
+		_, err = loadImageCatalog(filepath.Join(dir, "missing.json"))
+		assert.Error(t, err)
+	})
+}
+
+func TestConfigureWithImagesCatalog(t *testing.T) {
+	withTmpDir(t, "test-catalog", func(t *testing.T, dir string) {
+		catalogFile := filepath.Join(dir, "catalog.json")
+		data := `{"hardened-firefox": {"image": "selenoid/firefox", "port": "5555", "tagFilter": "^4", "shmSize": 256, "env": ["TZ=UTC"]}}`
+		assert.NoError(t, os.WriteFile(catalogFile, []byte(data), 0644))
+		c, err := NewDockerConfigurator(&LifecycleConfig{
+			ConfigDir:     dir,
+			RegistryUrl:   mockDockerServer.URL,
+			ImagesCatalog: catalogFile,
+			Browsers:      "hardened-firefox",
+			LastVersions:  5,
+			Quiet:         true,
+		})
+		assert.NoError(t, err)
+		defer c.Close()
+		cfg, err := c.Configure(context.Background())
+		assert.NoError(t, err)
+		versions := (*cfg)["hardened-firefox"]
+		assert.Equal(t, "46.0", versions.Default)
+		assert.Len(t, versions.Versions, 2)
+		browser := versions.Versions["45.0"]
+		assert.Equal(t, c.browsersRegistry.imageRef("selenoid/firefox:45.0"), browser.Image)
+		assert.Equal(t, "5555", browser.Port)
+		assert.Equal(t, defaultBrowserPath, browser.Path)
+		assert.Equal(t, int64(268435456), browser.ShmSize)
+		assert.Equal(t, []string{"TZ=UTC"}, browser.Env)
+	})
+}
+
+func TestConfigureWithFullyQualifiedCatalogImage(t *testing.T) {
+	var authorization string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/v2/team/chrome/tags/list", func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_, _ = fmt.Fprintln(w, `{"name":"team/chrome", "tags": ["120.0", "121.0"]}`)
+	})
+	srv := httptest.NewTLSServer(mux)
+	defer srv.Close()
+	host := hostPort(srv.URL)
+	withTmpDir(t, "test-catalog", func(t *testing.T, dir string) {
+		catalogFile := filepath.Join(dir, "catalog.json")
+		data := fmt.Sprintf(`{"team-chrome": {"image": "%s/team/chrome"}}`, host)
+		assert.NoError(t, os.WriteFile(catalogFile, []byte(data), 0644))
+		c, err := NewDockerConfigurator(&LifecycleConfig{
+			ConfigDir:        dir,
+			RegistryUrl:      mockDockerServer.URL,
+			InsecureRegistry: true,
+			ImagesCatalog:    catalogFile,
+			Browsers:         "team-chrome",
+			Quiet:            true,
+		})
+		assert.NoError(t, err)
+		defer c.Close()
+		c.authConfigs[host] = &configtypes.AuthConfig{Username: "team-user", Password: "team-password"}
+		cfg, err := c.Configure(context.Background())
+		assert.NoError(t, err)
+		versions := (*cfg)["team-chrome"]
+		assert.Equal(t, "121.0", versions.Default)
+		assert.Equal(t, host+"/team/chrome:120.0", versions.Versions["120.0"].Image)
+		assert.Equal(t, "Basic "+base64.StdEncoding.EncodeToString([]byte("team-user:team-password")), authorization)
+	})
+}
+
+func TestGetImageRegistry(t *testing.T) {
+	c := &DockerConfigurator{RegistryUrl: DefaultRegistryUrl, BrowsersRegistryUrl: "https://mirror.example.com"}
+	assert.NoError(t, c.initRegistries())
+	r, repository, err := c.getImageRegistry("selenoid/chrome")
+	assert.NoError(t, err)
+	assert.Equal(t, c.browsersRegistry, r)
+	assert.Equal(t, "selenoid/chrome", repository)
+	r, repository, err = c.getImageRegistry("docker.io/selenoid/chrome")
+	assert.NoError(t, err)
+	assert.Equal(t, c.selenoidRegistry, r)
+	assert.Equal(t, "selenoid/chrome", repository)
+	r, repository, err = c.getImageRegistry("registry.example.com/team/chrome")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://registry.example.com", r.url)
+	assert.Equal(t, "team/chrome", repository)
+	assert.Equal(t, "registry.example.com/team/chrome", r.imageRef(repository))
+	same, _, _ := c.getImageRegistry("registry.example.com/team/firefox")
+	assert.Same(t, r, same)
+}
+
+func TestConfigurePlaywright(t *testing.T) {
+	withTmpDir(t, "test-playwright", func(t *testing.T, dir string) {
+		c, err := NewDockerConfigurator(&LifecycleConfig{
+			ConfigDir:   dir,
+			RegistryUrl: mockDockerServer.URL,
+			Browsers:    "playwright-chromium;opera",
+			Quiet:       true,
+		})
+		assert.NoError(t, err)
+		defer c.Close()
+		cfg, err := c.Configure(context.Background())
+		assert.NoError(t, err)
+		versions := (*cfg)[playwrightChromium]
+		assert.Equal(t, "1.42.1", versions.Default)
+		assert.Len(t, versions.Versions, 2)
+		browser := versions.Versions["1.41.2"]
+		assert.Equal(t, c.browsersRegistry.imageRef("browsers/playwright-chromium:1.41.2"), browser.Image)
+		assert.Equal(t, "3000", browser.Port)
+		assert.Equal(t, "/", browser.Path)
+		assert.Equal(t, map[string]string{protocolLabel: ProtocolPlaywright}, browser.Labels)
+		assert.Equal(t, defaultBrowserPort, (*cfg)[opera].Versions["44.0"].Port)
+		assert.Nil(t, (*cfg)[opera].Versions["44.0"].Labels)
+
+		status := c.Status()
+		assert.True(t, status.Configured)
+		assert.Equal(t, []BrowserStatus{
+			{Name: opera, Protocol: ProtocolWebDriver, Default: "44.0", Versions: []string{"44.0"}},
+			{Name: playwrightChromium, Protocol: ProtocolPlaywright, Default: "1.42.1", Versions: []string{"1.42.1", "1.41.2"}},
+		}, status.Browsers)
+	})
+}
+
+func TestBrowsersStatusNullVersion(t *testing.T) {
+	cfg := SelenoidConfig{
+		playwrightChromium: {Default: "1.42.1", Versions: map[string]*config.Browser{
+			"1.42.1": {Image: "browsers/playwright-chromium:1.42.1", Labels: map[string]string{protocolLabel: ProtocolPlaywright}},
+			"1.41.2": nil,
+		}},
+	}
+	assert.Equal(t, []BrowserStatus{
+		{Name: playwrightChromium, Protocol: ProtocolPlaywright, Default: "1.42.1", Versions: []string{"1.42.1", "1.41.2"}},
+	}, browsersStatus(cfg))
+}
@@ -0,0 +1,128 @@
+package selenoid
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const checksumFileSuffix = ".sha256"
+
+// isChecksumAsset tells release checksum files (e.g. checksums.txt, SHA256SUMS or selenoid_linux_amd64.sha256) from binaries
+func isChecksumAsset(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, checksumFileSuffix) || strings.Contains(lower, "checksum") || strings.Contains(lower, "sha256sum")
+}
+
+// findChecksumAsset returns checksum asset for specified binary: dedicated <name>.sha256 file is preferred over common one
+func findChecksumAsset(assetNames []string, binaryName string) (string, bool) {
+	var common string
+	for _, name := range assetNames {
+		if name == binaryName+checksumFileSuffix {
+			return name, true
+		}
+		if common == "" && isChecksumAsset(name) && !strings.HasSuffix(strings.ToLower(name), checksumFileSuffix) {
+			common = name
+		}
+	}
+	return common, common != ""
+}
+
+// parseChecksumFile supports sha256sum output format ("<hash>  <file name>" per line) and files containing a single hash
+func parseChecksumFile(data []byte, fileName string) (string, error) {
+	var lines [][]string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) > 0 {
+			lines = append(lines, fields)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read checksum file: %v", err)
+	}
+	for _, fields := range lines {
+		if len(fields) >= 2 && strings.TrimPrefix(fields[len(fields)-1], "*") == fileName {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if len(lines) == 1 && len(lines[0]) == 1 {
+		return strings.ToLower(lines[0][0]), nil
+	}
+	return "", fmt.Errorf("checksum for %s not found", fileName)
+}
+
+func sha256Sum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func verifyChecksum(actual string, expected string) error {
+	if expected != "" && !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("checksum mismatch: expected sha256 %s but got %s", strings.ToLower(expected), actual)
+	}
+	return nil
+}
+
+// verifyFileChecksum compares SHA-256 of file contents with expected one if it is known
+func verifyFileChecksum(path string, expected string) error {
+	if expected == "" {
+		return nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	cw := newChecksumWriter(io.Discard)
+	_, err = io.Copy(cw, f)
+	if err != nil {
+		return err
+	}
+	return verifyChecksum(cw.Sum(), expected)
+}
+
+// checksumWriter computes SHA-256 of everything written to underlying writer
+type checksumWriter struct {
+	io.Writer
+	hash hash.Hash
+}
+
+func newChecksumWriter(w io.Writer) *checksumWriter {
+	h := sha256.New()
+	return &checksumWriter{Writer: io.MultiWriter(w, h), hash: h}
+}
+
+func (w *checksumWriter) Sum() string {
+	return hex.EncodeToString(w.hash.Sum(nil))
+}
+
+// replaceFile writes file contents to a temporary file in the same directory and then renames it, so that previous file is left untouched on errors
+func replaceFile(outputPath string, mode os.FileMode, write func(io.Writer) error) error {
+	dir := filepath.Dir(outputPath)
+	f, err := os.CreateTemp(dir, "."+filepath.Base(outputPath)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := f.Name()
+	defer os.Remove(tmpPath)
+	err = write(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	err = os.Chmod(tmpPath, mode)
+	if err != nil {
+		return err
+	}
+	return os.Rename(tmpPath, outputPath)
+}
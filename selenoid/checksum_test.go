@@ -0,0 +1,41 @@
+package selenoid
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestParseChecksumFile(t *testing.T) {
+	data := []byte("AB12  selenoid_linux_amd64\ncd34 *selenoid_darwin_amd64\n")
+	checksum, err := parseChecksumFile(data, "selenoid_linux_amd64")
+	assert.NoError(t, err)
+	assert.Equal(t, "ab12", checksum)
+	checksum, err = parseChecksumFile(data, "selenoid_darwin_amd64")
+	assert.NoError(t, err)
+	assert.Equal(t, "cd34", checksum)
+	_, err = parseChecksumFile(data, "selenoid_windows_amd64.exe")
+	assert.Error(t, err)
+
+	checksum, err = parseChecksumFile([]byte("ef56\n"), "selenoid_linux_amd64")
+	assert.NoError(t, err)
+	assert.Equal(t, "ef56", checksum)
+}
+
+func TestFindChecksumAsset(t *testing.T) {
+	name, ok := findChecksumAsset([]string{"selenoid_linux_amd64", "checksums.txt", "selenoid_linux_amd64.sha256"}, "selenoid_linux_amd64")
+	assert.True(t, ok)
+	assert.Equal(t, "selenoid_linux_amd64.sha256", name)
+	name, ok = findChecksumAsset([]string{"selenoid_linux_amd64", "SHA256SUMS"}, "selenoid_linux_amd64")
+	assert.True(t, ok)
+	assert.Equal(t, "SHA256SUMS", name)
+	_, ok = findChecksumAsset([]string{"selenoid_linux_amd64", "selenoid_darwin_amd64.sha256"}, "selenoid_linux_amd64")
+	assert.False(t, ok)
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	sum := sha256Sum([]byte("data"))
+	assert.NoError(t, verifyChecksum(sum, ""))
+	assert.NoError(t, verifyChecksum(sum, sum))
+	assert.Error(t, verifyChecksum(sum, sha256Sum([]byte("other"))))
+}
@@ -0,0 +1,165 @@
+package selenoid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	composeFileName = "docker-compose.yml"
+
+	// Compose substitutes this variable by the directory compose is launched from
+	composeDirVar = "${PWD}"
+)
+
+type composeFile struct {
+	Services map[string]*composeService `yaml:"services"`
+	Networks map[string]*composeNetwork `yaml:"networks,omitempty"`
+}
+
+type composeService struct {
+	Image         string            `yaml:"image"`
+	ContainerName string            `yaml:"container_name"`
+	Hostname      string            `yaml:"hostname,omitempty"`
+	Command       []string          `yaml:"command,omitempty"`
+	Environment   []string          `yaml:"environment,omitempty"`
+	Volumes       []string          `yaml:"volumes,omitempty"`
+	Ports         []string          `yaml:"ports,omitempty"`
+	Networks      []string          `yaml:"networks,omitempty"`
+	Restart       string            `yaml:"restart,omitempty"`
+	UsernsMode    string            `yaml:"userns_mode,omitempty"`
+	SecurityOpt   []string          `yaml:"security_opt,omitempty"`
+	Labels        map[string]string `yaml:"labels,omitempty"`
+	DependsOn     []string          `yaml:"depends_on,omitempty"`
+}
+
+// Explicit name prevents compose from prefixing network with project name as Selenoid refers to it in -container-network
+type composeNetwork struct {
+	Name string `yaml:"name"`
+}
+
+// ExportCompose saves Selenoid and Selenoid UI container definitions as docker-compose.yml and browsers.json to output directory
+func (c *DockerConfigurator) ExportCompose(ctx context.Context, outputDir string, uiPort int) error {
+	browsersJson, err := c.getBrowsersJsonData(ctx)
+	if err != nil {
+		return err
+	}
+	selenoidCfg := c.getSelenoidContainerConfig(
+		c.getImageRef(ctx, selenoidImage, c.Version),
+		composeDirVar,
+		composeDirVar+"/"+videoDirName,
+		composeDirVar+"/"+logsDirName,
+		c.getSocketVolume(),
+	)
+
+	// Selenoid specific args, environment and version are not applicable to Selenoid UI
+	ui := *c
+	ui.Args = ""
+	ui.Env = ""
+	ui.Port = uiPort
+	ui.Version = Latest
+	uiCfg := ui.getSelenoidUIContainerConfig(
+		ui.getImageRef(ctx, selenoidUIImage, ui.Version),
+		fmt.Sprintf("http://%s:%d", selenoidCfg.Name, DefaultPort),
+	)
+	uiService := ui.getComposeService(uiCfg)
+	uiService.DependsOn = []string{ServiceSelenoid}
+
+	compose := composeFile{
+		Services: map[string]*composeService{
+			ServiceSelenoid:   c.getComposeService(selenoidCfg),
+			ServiceSelenoidUI: uiService,
+		},
+		Networks: map[string]*composeNetwork{
+			selenoidCfg.Network: {Name: selenoidCfg.Network},
+		},
+	}
+	data, err := yaml.Marshal(&compose)
+	if err != nil {
+		return fmt.Errorf("failed to marshal compose file: %v", err)
+	}
+	err = ctx.Err()
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(outputDir, os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+	err = os.WriteFile(getComposeFilePath(outputDir), data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to save compose file: %v", err)
+	}
+	err = os.WriteFile(getSelenoidConfigPath(outputDir), browsersJson, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to save browsers.json: %v", err)
+	}
+	return nil
+}
+
+func getComposeFilePath(outputDir string) string {
+	return filepath.Join(outputDir, composeFileName)
+}
+
+// getImageRef prefers already downloaded image and falls back to the one that would be downloaded
+func (c *DockerConfigurator) getImageRef(ctx context.Context, imageName string, version string) string {
+	if img := c.getImage(imageName, version); img != nil {
+		return img.RepoTags[0]
+	}
+	return c.resolveImageRef(ctx, imageName, version)
+}
+
+func (c *DockerConfigurator) getBrowsersJsonData(ctx context.Context) ([]byte, error) {
+	configPath := getSelenoidConfigPath(c.ConfigDir)
+	if c.BrowsersJson != "" {
+		configPath = c.BrowsersJson
+	}
+	if fileExists(configPath) {
+		data, err := os.ReadFile(configPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read browsers.json from %s: %v", configPath, err)
+		}
+		return data, nil
+	}
+	c.DownloadNeeded = false
+	cfg, err := c.createConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(cfg, "", "    ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal json: %v", err)
+	}
+	return data, nil
+}
+
+// Host environment is not exported to keep compose file reproducible on other machines
+func (c *DockerConfigurator) getComposeService(cfg *containerConfig) *composeService {
+	service := &composeService{
+		Image:         cfg.Image,
+		ContainerName: cfg.Name,
+		Hostname:      "localhost",
+		Command:       cfg.Cmd,
+		Environment:   c.getContainerEnv(cfg, nil),
+		Volumes:       cfg.Volumes,
+		Restart:       "always",
+		UsernsMode:    cfg.UserNS,
+		SecurityOpt:   cfg.SecurityOpt,
+		Labels: map[string]string{
+			serviceLabel:  cfg.Service,
+			instanceLabel: c.Instance,
+		},
+	}
+	if cfg.Network != "" {
+		service.Networks = []string{cfg.Network}
+	}
+	if cfg.HostPort > 0 && cfg.ServicePort > 0 {
+		service.Ports = []string{fmt.Sprintf("%d:%d", cfg.HostPort, cfg.ServicePort)}
+	}
+	return service
+}
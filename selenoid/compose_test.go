@@ -0,0 +1,63 @@
+package selenoid
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestExportCompose(t *testing.T) {
+	withTmpDir(t, "test-export-compose", func(t *testing.T, dir string) {
+		configDir := filepath.Join(dir, "config")
+		assert.NoError(t, os.MkdirAll(configDir, os.ModePerm))
+		browsersJson := []byte(`{"firefox": {"default": "46.0", "versions": {"46.0": {"image": "selenoid/firefox:46.0", "port": "4444"}}}}`)
+		assert.NoError(t, os.WriteFile(getSelenoidConfigPath(configDir), browsersJson, 0644))
+
+		c, err := NewDockerConfigurator(&LifecycleConfig{
+			ConfigDir:   configDir,
+			RegistryUrl: mockDockerServer.URL,
+			Port:        4445,
+			Version:     Latest,
+			Args:        "-limit 5",
+			Quiet:       true,
+		})
+		assert.NoError(t, err)
+		defer c.Close()
+		outputDir := filepath.Join(dir, "output")
+		assert.NoError(t, c.ExportCompose(context.Background(), outputDir, 8081))
+
+		data, err := os.ReadFile(getSelenoidConfigPath(outputDir))
+		assert.NoError(t, err)
+		assert.Equal(t, browsersJson, data)
+
+		data, err = os.ReadFile(getComposeFilePath(outputDir))
+		assert.NoError(t, err)
+		var compose composeFile
+		assert.NoError(t, yaml.Unmarshal(data, &compose))
+		assert.Equal(t, map[string]*composeNetwork{networkName: {Name: networkName}}, compose.Networks)
+
+		selenoid := compose.Services[ServiceSelenoid]
+		assert.NotNil(t, selenoid)
+		assert.Equal(t, "docker.io/aerokube/selenoid:latest", selenoid.Image)
+		assert.Equal(t, selenoidContainerName, selenoid.ContainerName)
+		assert.Equal(t, []string{"4445:4444"}, selenoid.Ports)
+		assert.Equal(t, []string{networkName}, selenoid.Networks)
+		assert.Equal(t, "always", selenoid.Restart)
+		assert.Equal(t, []string{"-limit", "5", "-conf", "/etc/selenoid/browsers.json"}, selenoid.Command[:4])
+		assert.Contains(t, selenoid.Volumes, "${PWD}:/etc/selenoid:ro,Z")
+		assert.Contains(t, selenoid.Environment, "OVERRIDE_VIDEO_OUTPUT_DIR=${PWD}/video")
+
+		ui := compose.Services[ServiceSelenoidUI]
+		assert.NotNil(t, ui)
+		assert.True(t, strings.HasSuffix(ui.Image, "aerokube/selenoid-ui:1.5.2"), ui.Image)
+		assert.Equal(t, selenoidUIContainerName, ui.ContainerName)
+		assert.Equal(t, []string{"8081:8080"}, ui.Ports)
+		assert.Equal(t, []string{"--selenoid-uri=http://selenoid:4444"}, ui.Command)
+		assert.Equal(t, []string{ServiceSelenoid}, ui.DependsOn)
+	})
+}
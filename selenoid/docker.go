@@ -10,7 +10,6 @@ import (
 	"io"
 	"log"
 	"net/http"
-	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -18,6 +17,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/docker/docker/api"
@@ -26,6 +26,7 @@ import (
 	"github.com/Masterminds/semver/v3"
 	"github.com/aerokube/selenoid/config"
 	authconfig "github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/config/configfile"
 	configtypes "github.com/docker/cli/cli/config/types"
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
@@ -34,6 +35,8 @@ import (
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/docker/api/types/strslice"
 	"github.com/docker/docker/client"
+	"github.com/docker/docker/errdefs"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/docker/go-connections/nat"
 	"github.com/heroku/docker-registry-client/registry"
 	"github.com/mattn/go-colorable"
@@ -59,6 +62,7 @@ const (
 	ggrUIContainerName      = "ggr-ui"
 	selenoidUIContainerName = "selenoid-ui"
 	overrideHome            = "OVERRIDE_HOME"
+	dockerSocket            = "/var/run/docker.sock"
 	dockerApiVersion        = "DOCKER_API_VERSION"
 )
 
@@ -77,20 +81,41 @@ type DockerConfigurator struct {
 	UserNSAware
 	LogsAware
 	GracefulAware
-	LastVersions int
-	Pull         bool
-	RegistryUrl  string
-	BrowsersJson string
-	ShmSize      int
-	Tmpfs        int
-	VNC          bool
-	docker       *client.Client
-	reg          *registry.Registry
-	authConfig   *configtypes.AuthConfig
-	registryHost string
+	InstanceAware
+	RetryAware
+	ParallelAware
+	HTTPAware
+	MergeAware
+	LastVersions        int
+	Pull                bool
+	RegistryUrl         string
+	BrowsersRegistryUrl string
+	RegistryUsername    string
+	RegistryPassword    string
+	BrowsersUsername    string
+	BrowsersPassword    string
+	InsecureRegistry    bool
+	BrowsersJson        string
+	ImagesCatalog       string
+	ShmSize             int
+	Tmpfs               int
+	VNC                 bool
+	runtime             string
+	hostSocket          string
+	docker              *client.Client
+	selenoidRegistry    *imageRegistry
+	browsersRegistry    *imageRegistry
+	registries          map[string]*imageRegistry
+	dockerConfig        *configfile.ConfigFile
+	authConfigs         map[string]*configtypes.AuthConfig
+	catalog             ImageCatalog
 }
 
 func NewDockerConfigurator(config *LifecycleConfig) (*DockerConfigurator, error) {
+	return newDockerConfigurator(config, ModeDocker, dockerSocket)
+}
+
+func newDockerConfigurator(config *LifecycleConfig, runtime string, hostSocket string, opts ...client.Opt) (*DockerConfigurator, error) {
 	c := &DockerConfigurator{
 		Logger:                 Logger{Quiet: config.Quiet},
 		ConfigDirAware:         ConfigDirAware{ConfigDir: config.ConfigDir},
@@ -104,31 +129,48 @@ func NewDockerConfigurator(config *LifecycleConfig) (*DockerConfigurator, error)
 		UserNSAware:            UserNSAware{UserNS: config.UserNS},
 		LogsAware:              LogsAware{DisableLogs: config.DisableLogs},
 		GracefulAware:          GracefulAware{Graceful: config.Graceful, GracefulTimeout: config.GracefulTimeout},
+		InstanceAware:          InstanceAware{Instance: config.Instance},
+		RetryAware:             RetryAware{Retries: config.Retries, RetryBackoff: config.RetryBackoff},
+		ParallelAware:          ParallelAware{Parallel: config.Parallel},
+		HTTPAware:              HTTPAware{CACert: config.CACert, HTTPTimeout: config.HTTPTimeout},
+		MergeAware:             MergeAware{Merge: config.Merge},
 		RegistryUrl:            config.RegistryUrl,
+		BrowsersRegistryUrl:    config.BrowsersRegistryUrl,
+		RegistryUsername:       config.RegistryUsername,
+		RegistryPassword:       config.RegistryPassword,
+		BrowsersUsername:       config.BrowsersUsername,
+		BrowsersPassword:       config.BrowsersPassword,
+		InsecureRegistry:       config.InsecureRegistry,
 		BrowsersJson:           config.BrowsersJson,
+		ImagesCatalog:          config.ImagesCatalog,
 		LastVersions:           config.LastVersions,
 		ShmSize:                config.ShmSize,
 		Tmpfs:                  config.Tmpfs,
 		VNC:                    config.VNC,
+		runtime:                runtime,
+		hostSocket:             hostSocket,
 	}
 	if c.Quiet {
 		log.SetFlags(0)
 		log.SetOutput(io.Discard)
 	}
-	err := c.initDockerClient()
+	err := c.initDockerClient(opts...)
 	if err != nil {
 		return nil, fmt.Errorf("new configurator: %v", err)
 	}
-	authConfig, err := c.initAuthConfig()
+	err = c.initRegistries()
 	if err != nil {
-		c.Errorf("Failed to load authentication configuration, using default values: %v", err)
-	} else {
-		c.authConfig = authConfig
+		return nil, fmt.Errorf("new configurator: %v", err)
+	}
+	c.catalog, err = loadImageCatalog(c.ImagesCatalog)
+	if err != nil {
+		return nil, fmt.Errorf("new configurator: %v", err)
 	}
 	return c, nil
 }
 
-func createCompatibleDockerClient(onVersionSpecified, onVersionDetermined, onUsingDefaultVersion func(string)) (*client.Client, error) {
+func createCompatibleDockerClient(onVersionSpecified, onVersionDetermined, onUsingDefaultVersion func(string), opts ...client.Opt) (*client.Client, error) {
+	opts = append([]client.Opt{client.FromEnv}, opts...)
 	dockerApiVersionEnv := os.Getenv(dockerApiVersion)
 	if dockerApiVersionEnv != "" {
 		onVersionSpecified(dockerApiVersionEnv)
@@ -139,7 +181,7 @@ func createCompatibleDockerClient(onVersionSpecified, onVersionDetermined, onUsi
 			for minorVersion := maxMinorVersion; minorVersion >= minMinorVersion; minorVersion-- {
 				apiVersion := fmt.Sprintf("%d.%d", majorVersion, minorVersion)
 				_ = os.Setenv(dockerApiVersion, apiVersion)
-				docker, err := client.NewClientWithOpts(client.FromEnv)
+				docker, err := client.NewClientWithOpts(opts...)
 				if err != nil {
 					return nil, err
 				}
@@ -152,7 +194,7 @@ func createCompatibleDockerClient(onVersionSpecified, onVersionDetermined, onUsi
 		}
 		onUsingDefaultVersion(api.DefaultVersion)
 	}
-	return client.NewClientWithOpts(client.FromEnv)
+	return client.NewClientWithOpts(opts...)
 }
 
 func parseVersion(ver string) (int, int) {
@@ -178,7 +220,7 @@ func isDockerAPIVersionCorrect(docker *client.Client) bool {
 	return apiInfo.APIVersion == docker.ClientVersion()
 }
 
-func (c *DockerConfigurator) initDockerClient() error {
+func (c *DockerConfigurator) initDockerClient(opts ...client.Opt) error {
 	docker, err := createCompatibleDockerClient(
 		func(specifiedApiVersion string) {
 			c.Pointf("Using Docker API version: %s", specifiedApiVersion)
@@ -189,6 +231,7 @@ func (c *DockerConfigurator) initDockerClient() error {
 		func(defaultApiVersion string) {
 			c.Pointf("Did not manage to determine your Docker API version - using default version: %s", defaultApiVersion)
 		},
+		opts...,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to init Docker client: %v", err)
@@ -197,42 +240,67 @@ func (c *DockerConfigurator) initDockerClient() error {
 	return nil
 }
 
-func (c *DockerConfigurator) initAuthConfig() (*configtypes.AuthConfig, error) {
+// initRegistries prepares registries of Selenoid and browser images, explicit credentials of every registry replace saved ones
+func (c *DockerConfigurator) initRegistries() error {
 	configFile, err := authconfig.Load("")
 	if err != nil {
-		return nil, err
+		c.Errorf("Failed to load authentication configuration, using default values: %v", err)
+	} else {
+		c.dockerConfig = configFile
 	}
-	u, err := url.Parse(c.RegistryUrl)
+	c.selenoidRegistry, err = newImageRegistry(c.RegistryUrl)
 	if err != nil {
-		return nil, err
+		return err
 	}
-
-	registryHost := u.Host
-	if c.RegistryUrl != DefaultRegistryUrl {
-		c.registryHost = registryHost
+	c.browsersRegistry = c.selenoidRegistry
+	if c.BrowsersRegistryUrl != "" && c.BrowsersRegistryUrl != c.RegistryUrl {
+		c.browsersRegistry, err = newImageRegistry(c.BrowsersRegistryUrl)
+		if err != nil {
+			return err
+		}
 	}
-	if cfg, ok := configFile.AuthConfigs[registryHost]; ok {
-		c.Titlef(`Loaded authentication data for "%s"`, registryHost)
-		return &cfg, nil
+	c.authConfigs = make(map[string]*configtypes.AuthConfig)
+	if c.RegistryUsername != "" {
+		c.authConfigs[c.selenoidRegistry.host] = &configtypes.AuthConfig{
+			Username:      c.RegistryUsername,
+			Password:      c.RegistryPassword,
+			ServerAddress: getAuthKey(c.selenoidRegistry.host),
+		}
 	}
-
-	return nil, nil
+	if c.BrowsersUsername != "" {
+		c.authConfigs[c.browsersRegistry.host] = &configtypes.AuthConfig{
+			Username:      c.BrowsersUsername,
+			Password:      c.BrowsersPassword,
+			ServerAddress: getAuthKey(c.browsersRegistry.host),
+		}
+	}
+	return nil
 }
 
-func (c *DockerConfigurator) getRegistryClient() *registry.Registry {
-	if c.reg != nil {
-		return c.reg
-	}
+func (c *DockerConfigurator) getRegistryClient(r *imageRegistry) (*registry.Registry, error) {
+	r.clientOnce.Do(func() {
+		r.client, r.clientErr = c.newRegistryClient(r)
+		if r.clientErr != nil {
+			c.Errorf("%v", r.clientErr)
+		}
+	})
+	return r.client, r.clientErr
+}
 
-	u := strings.TrimSuffix(c.RegistryUrl, "/")
+func (c *DockerConfigurator) newRegistryClient(r *imageRegistry) (*registry.Registry, error) {
+	u := r.url
 	username, password := "", ""
-	if c.authConfig != nil {
-		username, password = c.authConfig.Username, c.authConfig.Password
+	if authConfig := c.getAuthConfig(r.host); authConfig != nil {
+		username, password = authConfig.Username, authConfig.Password
+	}
+	transport, err := c.newHTTPTransport(c.InsecureRegistry)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize registry client: %v", err)
 	}
 	reg := &registry.Registry{
 		URL: u,
 		Client: &http.Client{
-			Transport: registry.WrapTransport(http.DefaultTransport, u, username, password),
+			Transport: registry.WrapTransport(transport, u, username, password),
 		},
 		Logf: func(format string, args ...interface{}) {
 			c.Tracef(format, args...)
@@ -240,12 +308,9 @@ func (c *DockerConfigurator) getRegistryClient() *registry.Registry {
 	}
 
 	if err := reg.Ping(); err != nil {
-		c.Errorf("Docker Registry is not available: %v", err)
-		return nil
+		return nil, fmt.Errorf("Docker Registry is not available: %v", err)
 	}
-
-	c.reg = reg
-	return reg
+	return reg, nil
 }
 
 func (c *DockerConfigurator) Close() error {
@@ -255,40 +320,113 @@ func (c *DockerConfigurator) Close() error {
 	return nil
 }
 
-func (c *DockerConfigurator) Status() {
-	selenoidImage := c.getSelenoidImage()
-	if selenoidImage != nil {
-		c.Pointf("Using Selenoid image: %s (%s)", selenoidImage.RepoTags[0], selenoidImage.ID)
-	} else {
-		c.Pointf("Selenoid image is not present")
+func (c *DockerConfigurator) Status() *ServiceStatus {
+	status := &ServiceStatus{
+		Service:       ServiceSelenoid,
+		Instance:      c.Instance,
+		Mode:          c.runtime,
+		ConfigDir:     c.ConfigDir,
+		ContainerName: c.instanceName(selenoidContainerName),
+	}
+	fillImageStatus(status, c.getSelenoidImage())
+	fillConfigStatus(status, getSelenoidConfigPath(c.ConfigDir))
+	fillContainerStatus(status, c.getSelenoidContainer())
+	return status
+}
+
+func (c *DockerConfigurator) UIStatus() *ServiceStatus {
+	status := &ServiceStatus{
+		Service:       ServiceSelenoidUI,
+		Instance:      c.Instance,
+		Mode:          c.runtime,
+		ContainerName: c.instanceName(selenoidUIContainerName),
 	}
-	configPath := getSelenoidConfigPath(c.ConfigDir)
-	c.Pointf("Selenoid configuration directory is %s", c.ConfigDir)
-	if fileExists(configPath) {
-		c.Pointf("Selenoid configuration file is %s", configPath)
-	} else {
-		c.Pointf("Selenoid is not configured")
+	fillImageStatus(status, c.getSelenoidUIImage())
+	fillContainerStatus(status, c.getSelenoidUIContainer())
+	return status
+}
+
+// List returns all Selenoid and Selenoid UI containers started by cm
+func (c *DockerConfigurator) List() ([]*ServiceStatus, error) {
+	f := filters.NewArgs()
+	f.Add("label", serviceLabel)
+	containers, err := c.docker.ContainerList(context.Background(), container.ListOptions{All: true, Filters: f})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %v", err)
+	}
+	ret := []*ServiceStatus{}
+	found := make(map[string]bool)
+	for i := range containers {
+		ctr := &containers[i]
+		service, ok := ctr.Labels[serviceLabel]
+		if !ok {
+			continue
+		}
+		status := &ServiceStatus{
+			Service:  service,
+			Instance: ctr.Labels[instanceLabel],
+			Mode:     c.runtime,
+		}
+		fillListedContainerStatus(status, ctr)
+		found[ctr.ID] = true
+		ret = append(ret, status)
+	}
+	// Containers created by previous cm versions have no labels
+	for _, legacy := range []struct{ service, name string }{
+		{ServiceSelenoid, selenoidContainerName},
+		{ServiceSelenoidUI, selenoidUIContainerName},
+	} {
+		if ctr := c.getContainer(legacy.name); ctr != nil && !found[ctr.ID] {
+			status := &ServiceStatus{Service: legacy.service, Mode: c.runtime}
+			fillListedContainerStatus(status, ctr)
+			found[ctr.ID] = true
+			ret = append(ret, status)
+		}
 	}
-	selenoidContainer := c.getSelenoidContainer()
-	if selenoidContainer != nil {
-		c.Pointf("Selenoid container is running: %s (%s)", selenoidContainerName, selenoidContainer.ID)
-	} else {
-		c.Pointf("Selenoid container is not running")
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].Instance != ret[j].Instance {
+			return ret[i].Instance < ret[j].Instance
+		}
+		return ret[i].Service < ret[j].Service
+	})
+	return ret, nil
+}
+
+func fillListedContainerStatus(status *ServiceStatus, ctr *types.Container) {
+	if len(ctr.Names) > 0 {
+		status.ContainerName = strings.TrimPrefix(ctr.Names[0], "/")
 	}
+	status.Downloaded = true
+	status.ImageRef = ctr.Image
+	status.ImageID = ctr.ImageID
+	fillContainerStatus(status, ctr)
+	status.Running = ctr.State == "running"
 }
 
-func (c *DockerConfigurator) UIStatus() {
-	selenoidUIImage := c.getSelenoidUIImage()
-	if selenoidUIImage != nil {
-		c.Pointf("Using Selenoid UI image: %s (%s)", selenoidUIImage.RepoTags[0], selenoidUIImage.ID)
-	} else {
-		c.Pointf("Selenoid UI image is not present")
+func fillImageStatus(status *ServiceStatus, img *image.Summary) {
+	if img == nil || len(img.RepoTags) == 0 {
+		return
 	}
-	selenoidUIContainer := c.getSelenoidUIContainer()
-	if selenoidUIContainer != nil {
-		c.Pointf("Selenoid UI container is running: %s (%s)", selenoidUIContainerName, selenoidUIContainer.ID)
-	} else {
-		c.Pointf("Selenoid UI container is not running")
+	status.Downloaded = true
+	status.ImageRef = img.RepoTags[0]
+	status.ImageID = img.ID
+	if pieces := strings.Split(img.RepoTags[0], colon); len(pieces) >= 2 {
+		status.Version = pieces[len(pieces)-1]
+	}
+}
+
+func fillContainerStatus(status *ServiceStatus, ctr *types.Container) {
+	if ctr == nil {
+		return
+	}
+	status.Running = true
+	status.ContainerID = ctr.ID
+	status.ContainerState = ctr.State
+	for _, p := range ctr.Ports {
+		if p.PublicPort != 0 {
+			status.Port = int(p.PublicPort)
+			break
+		}
 	}
 }
 
@@ -337,33 +475,44 @@ func findMatchingImage(images []image.Summary, name string, version string) *ima
 	return nil
 }
 
-func (c *DockerConfigurator) Download() (string, error) {
-	return c.downloadImpl(selenoidImage, c.Version, "failed to pull Selenoid image")
+func (c *DockerConfigurator) Download(ctx context.Context) (string, error) {
+	return c.downloadImpl(ctx, selenoidImage, c.Version, "failed to pull Selenoid image")
 }
 
-func (c *DockerConfigurator) DownloadUI() (string, error) {
-	return c.downloadImpl(selenoidUIImage, c.Version, "failed to pull Selenoid UI image")
+func (c *DockerConfigurator) DownloadUI(ctx context.Context) (string, error) {
+	return c.downloadImpl(ctx, selenoidUIImage, c.Version, "failed to pull Selenoid UI image")
 }
 
-func (c *DockerConfigurator) downloadImpl(imageName string, version string, errorMessage string) (string, error) {
+func (c *DockerConfigurator) downloadImpl(ctx context.Context, imageName string, version string, errorMessage string) (string, error) {
+	ref := c.resolveImageRef(ctx, imageName, version)
+	err := c.pullImage(ctx, ref)
+	if err != nil {
+		return "", fmt.Errorf("%s: %v", errorMessage, err)
+	}
+	return ref, nil
+}
+
+// resolveImageRef returns fully qualified image reference replacing latest version by the most recent registry tag
+func (c *DockerConfigurator) resolveImageRef(ctx context.Context, imageName string, version string) string {
 	if version == Latest {
-		latestVersion := c.getLatestImageVersion(imageName)
+		latestVersion := c.getLatestImageVersion(ctx, imageName)
 		if latestVersion != nil {
 			version = *latestVersion
 		}
 	}
-	ref := c.getFullyQualifiedImageRef(imageName)
+	ref := c.selenoidRegistry.imageRef(imageName)
 	if version != Latest {
 		ref = imageWithTag(ref, version)
 	}
-	if !c.pullImage(context.Background(), ref) {
-		return "", errors.New(errorMessage)
-	}
-	return ref, nil
+	return ref
 }
 
-func (c *DockerConfigurator) getLatestImageVersion(imageName string) *string {
-	tags := c.fetchImageTags(imageName)
+func (c *DockerConfigurator) getLatestImageVersion(ctx context.Context, imageName string) *string {
+	tags, err := c.fetchImageTags(ctx, c.selenoidRegistry, imageName)
+	if err != nil {
+		c.Errorf("%v", err)
+		return nil
+	}
 	if len(tags) > 0 {
 		return &tags[0]
 	}
@@ -374,24 +523,31 @@ func (c *DockerConfigurator) IsConfigured() bool {
 	return fileExists(getSelenoidConfigPath(c.ConfigDir))
 }
 
-func (c *DockerConfigurator) Configure() (*SelenoidConfig, error) {
+func (c *DockerConfigurator) Configure(ctx context.Context) (*SelenoidConfig, error) {
 	err := c.createConfigDir()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create output directory: %v", err)
 	}
 	if c.BrowsersJson != "" {
-		return c.syncWithConfig()
+		return c.syncWithConfig(ctx)
 	}
 
-	cfg := c.createConfig()
+	cfg, err := c.createConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err = c.mergeWithSavedConfig(c.ConfigDir, cfg)
+	if err != nil {
+		return nil, err
+	}
 	data, err := json.MarshalIndent(cfg, "", "    ")
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal json: %v", err)
 	}
-	return &cfg, os.WriteFile(getSelenoidConfigPath(c.ConfigDir), data, 0644)
+	return &cfg, writeSelenoidConfig(ctx, c.ConfigDir, data)
 }
 
-func (c *DockerConfigurator) syncWithConfig() (*SelenoidConfig, error) {
+func (c *DockerConfigurator) syncWithConfig(ctx context.Context) (*SelenoidConfig, error) {
 	c.Titlef(`Requested to sync configuration from "%v"...`, color.GreenString(c.BrowsersJson))
 	data, err := os.ReadFile(c.BrowsersJson)
 	if err != nil {
@@ -403,119 +559,185 @@ func (c *DockerConfigurator) syncWithConfig() (*SelenoidConfig, error) {
 		return nil, fmt.Errorf("failed to parse browsers.json from %s: %v", c.BrowsersJson, err)
 	}
 	if c.DownloadNeeded {
+		var refs []string
 		for _, versions := range cfg {
 			for _, version := range versions.Versions {
 				if ref, ok := version.Image.(string); ok {
-					ctx := context.Background()
-					if !c.pullImage(ctx, ref) {
-						return nil, fmt.Errorf("failed to pull image %s from browsers.json file %s", ref, c.BrowsersJson)
-					}
+					refs = append(refs, ref)
 				} else {
 					c.Pointf("Skipping non-Docker image specification: %v", version.Image)
 				}
 			}
 		}
-		c.pullVideoRecorderImage()
+		sort.Strings(refs)
+		refs = append(refs, c.selenoidRegistry.imageRef(videoRecorderImage))
+		var failures []string
+		for ref := range c.pullImages(ctx, refs) {
+			failures = append(failures, ref)
+		}
+		if len(failures) > 0 {
+			sort.Strings(failures)
+			return nil, fmt.Errorf("failed to pull images from browsers.json file %s: %s", c.BrowsersJson, strings.Join(failures, ", "))
+		}
 	}
-	return &cfg, os.WriteFile(getSelenoidConfigPath(c.ConfigDir), data, 0644)
+	return &cfg, writeSelenoidConfig(ctx, c.ConfigDir, data)
 }
 
-func (c *DockerConfigurator) createConfig() SelenoidConfig {
-	requestedBrowsers := parseRequestedBrowsers(&c.Logger, c.Browsers)
-	browsersToIterate := c.getBrowsersToIterate(requestedBrowsers)
-	browsers := make(map[string]config.Versions)
-	for browserName, img := range browsersToIterate {
-		c.Titlef(`Processing browser "%v"...`, color.GreenString(browserName))
-		tags := c.fetchImageTags(img)
-		if c.VNC {
-			c.Pointf("Requested to download VNC images but this feature is now deprecated as all images contain VNC.")
+// createConfig returns configuration for successfully pulled images and an error listing images that failed to be fetched
+func (c *DockerConfigurator) createConfig(ctx context.Context) (SelenoidConfig, error) {
+	requestedBrowsers, err := parseRequestedBrowsers(c.Browsers)
+	if err != nil {
+		return nil, err
+	}
+	browsersToIterate, err := c.getBrowsersToIterate(requestedBrowsers)
+	if err != nil {
+		return nil, err
+	}
+	var browserNames []string
+	for browserName := range browsersToIterate {
+		browserNames = append(browserNames, browserName)
+	}
+	sort.Strings(browserNames)
+	if c.VNC {
+		c.Pointf("Requested to download VNC images but this feature is now deprecated as all images contain VNC.")
+	}
+
+	// Registries and their clients are initialized before starting workers not to do this concurrently
+	registries := make([]*imageRegistry, len(browserNames))
+	repositories := make([]string, len(browserNames))
+	images := make([]string, len(browserNames))
+	browserTags := make([][]string, len(browserNames))
+	fetchErrors := make([]error, len(browserNames))
+	for i, browserName := range browserNames {
+		registries[i], repositories[i], fetchErrors[i] = c.getImageRegistry(browsersToIterate[browserName])
+		if fetchErrors[i] == nil {
+			c.getRegistryClient(registries[i])
+			images[i] = registries[i].imageRef(repositories[i])
 		}
-		versionConstraint := requestedBrowsers[browserName]
-		pulledTags := c.filterTags(tags, versionConstraint)
-		fullyQualifiedImage := c.getFullyQualifiedImageRef(img)
-		if c.DownloadNeeded {
-			pulledTags = c.pullImages(fullyQualifiedImage, pulledTags)
+	}
+	forEachParallel(c.Parallel, len(browserNames), func(i int) {
+		if fetchErrors[i] == nil {
+			browserTags[i], fetchErrors[i] = c.fetchImageTags(ctx, registries[i], repositories[i])
 		}
+	})
 
-		if len(pulledTags) > 0 {
-			browsers[browserName] = c.createVersions(browserName, fullyQualifiedImage, pulledTags)
+	var failures []string
+	var refs []string
+	for i, browserName := range browserNames {
+		c.Titlef(`Processing browser "%v"...`, color.GreenString(browserName))
+		if fetchErrors[i] != nil {
+			c.Errorf("%v", fetchErrors[i])
+			failures = append(failures, browsersToIterate[browserName])
+			continue
+		}
+		tags := c.catalog[browserName].matchingTags(browserTags[i])
+		browserTags[i] = c.filterTags(tags, requestedBrowsers[browserName])
+		for _, tag := range browserTags[i] {
+			refs = append(refs, imageWithTag(images[i], tag))
 		}
 	}
+
+	pullErrors := make(map[string]error)
 	if c.DownloadNeeded {
-		c.pullVideoRecorderImage()
+		refs = append(refs, c.selenoidRegistry.imageRef(videoRecorderImage))
+		c.Titlef("Pulling %d images...", len(refs))
+		pullErrors = c.pullImages(ctx, refs)
+		for ref := range pullErrors {
+			failures = append(failures, ref)
+		}
 	}
-	return browsers
-}
-
-func parseRequestedBrowsers(logger *Logger, requestedBrowsers string) map[string][]*semver.Constraints {
-	ret := make(map[string][]*semver.Constraints)
-	if requestedBrowsers != "" {
-		for _, section := range strings.Split(requestedBrowsers, semicolon) {
-			pieces := strings.Split(section, colon)
-			if len(pieces) >= 1 {
-				browserName := strings.TrimSpace(pieces[0])
-				if _, ok := ret[browserName]; !ok {
-					ret[browserName] = []*semver.Constraints{}
-				}
-				if len(pieces) == 2 {
-					versionConstraintString := strings.TrimSpace(pieces[1])
 
-					versionConstraint, err := semver.NewConstraint(versionConstraintString)
-					if err != nil {
-						logger.Errorf(`Invalid version constraint %s: %v - ignoring browser "%s"...`, versionConstraintString, err, browserName)
-						continue
-					}
-					ret[browserName] = append(ret[browserName], versionConstraint)
-				}
+	browsers := make(map[string]config.Versions)
+	for i, browserName := range browserNames {
+		var pulledTags []string
+		for _, tag := range browserTags[i] {
+			if _, failed := pullErrors[imageWithTag(images[i], tag)]; !failed {
+				pulledTags = append(pulledTags, tag)
 			}
 		}
+		if len(pulledTags) > 0 {
+			browsers[browserName] = c.createVersions(browserName, images[i], pulledTags)
+		}
 	}
-	return ret
+	if len(failures) > 0 {
+		sort.Strings(failures)
+		return browsers, fmt.Errorf("failed to fetch images: %s", strings.Join(failures, ", "))
+	}
+	return browsers, nil
 }
 
-func (c *DockerConfigurator) getBrowsersToIterate(requestedBrowsers map[string][]*semver.Constraints) map[string]string {
-	defaultBrowsers := map[string]string{
-		"firefox": "selenoid/firefox",
-		"chrome":  "selenoid/chrome",
-		"opera":   "selenoid/opera",
-	}
+// getBrowsersToIterate returns images of requested browsers or of default catalog entries when no browsers are requested
+func (c *DockerConfigurator) getBrowsersToIterate(requestedBrowsers map[string]*browserSelector) (map[string]string, error) {
+	ret := make(map[string]string)
 	if len(requestedBrowsers) > 0 {
-		if _, ok := requestedBrowsers[android]; ok {
-			defaultBrowsers[android] = "selenoid/android"
-		}
-		if _, ok := requestedBrowsers[edge]; ok {
-			defaultBrowsers[edge] = "browsers/edge"
-		}
-		ret := make(map[string]string)
+		var unsupported []string
 		for browserName := range requestedBrowsers {
-			if img, ok := defaultBrowsers[browserName]; ok {
-				ret[browserName] = img
+			if entry, ok := c.catalog[browserName]; ok {
+				ret[browserName] = entry.Image
 				continue
 			}
-			c.Errorf("Unsupported browser: %s", browserName)
+			unsupported = append(unsupported, browserName)
+		}
+		if len(unsupported) > 0 {
+			return nil, unsupportedBrowsersError(unsupported)
+		}
+		return ret, nil
+	}
+	for browserName, entry := range c.catalog {
+		if entry.Default {
+			ret[browserName] = entry.Image
 		}
-
-		return ret
 	}
-	return defaultBrowsers
+	return ret, nil
 }
 
-func (c *DockerConfigurator) fetchImageTags(image string) []string {
+func (c *DockerConfigurator) fetchImageTags(ctx context.Context, r *imageRegistry, image string) ([]string, error) {
 	c.Pointf(`Fetching tags for image %v`, color.BlueString(image))
-	reg := c.getRegistryClient()
-	if reg == nil {
-		c.Errorf(`Docker registry client not initialized`)
-		return nil
+	reg, err := c.getRegistryClient(r)
+	if err != nil {
+		return nil, err
 	}
-	tags, err := reg.Tags(image)
+	var tags []string
+	err = c.retry(ctx, &c.Logger, "Fetching tags", func() error {
+		var err error
+		tags, err = registryTags(ctx, reg, image)
+		return registryError(err)
+	})
 	if err != nil {
-		c.Errorf(`Failed to fetch tags for image "%s": %v`, image, err)
-		return nil
+		return nil, fmt.Errorf(`failed to fetch tags for image "%s": %v`, image, err)
 	}
 	tagsWithoutLatest := filterOutLatest(tags)
 	strSlice := Natural(tagsWithoutLatest)
 	sort.Sort(sort.Reverse(strSlice))
-	return tagsWithoutLatest
+	return tagsWithoutLatest, nil
+}
+
+// registryTags makes tags request cancellable as registry client does not support contexts
+func registryTags(ctx context.Context, reg *registry.Registry, image string) ([]string, error) {
+	type result struct {
+		tags []string
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		tags, err := reg.Tags(image)
+		ch <- result{tags, err}
+	}()
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-ch:
+		return r.tags, r.err
+	}
+}
+
+// registryError marks errors that can not be fixed by retrying (e.g. missing image or denied access) as permanent
+func registryError(err error) error {
+	var statusErr *registry.HTTPStatusError
+	if errors.As(err, &statusErr) && statusErr.Response != nil && !isRetryableStatus(statusErr.Response.StatusCode) {
+		return permanent(err)
+	}
+	return err
 }
 
 func filterOutLatest(tags []string) []string {
@@ -528,26 +750,26 @@ func filterOutLatest(tags []string) []string {
 	return ret
 }
 
-func (c *DockerConfigurator) filterTags(tags []string, versionConstraints []*semver.Constraints) []string {
-	if len(versionConstraints) > 0 {
-		var ret []string
-		for _, tag := range tags {
-			version, err := semver.NewVersion(tag)
-			if err != nil {
-				c.Errorf("Skipping tag %s as it does not follow semantic versioning: %v", tag, err)
-				continue
-			}
-			for _, vc := range versionConstraints {
-				if vc.Check(version) {
-					ret = append(ret, tag)
-				}
-			}
+// filterTags returns tags matching browser selector, --last-versions limit is applied unless selector contains version conditions
+func (c *DockerConfigurator) filterTags(tags []string, selector *browserSelector) []string {
+	if selector == nil {
+		selector = &browserSelector{}
+	}
+	var ret []string
+	for _, tag := range tags {
+		ok, err := selector.matches(tag)
+		if err != nil {
+			c.Errorf("Skipping tag %s as it does not follow semantic versioning: %v", tag, err)
+			continue
 		}
-		return ret
-	} else if c.LastVersions > 0 && c.LastVersions <= len(tags) {
-		return tags[:c.LastVersions]
+		if ok {
+			ret = append(ret, tag)
+		}
+	}
+	if last := selector.getLast(c.LastVersions); last > 0 && last < len(ret) {
+		return ret[:last]
 	}
-	return tags
+	return ret
 }
 
 func (c *DockerConfigurator) createVersions(browserName string, image string, tags []string) config.Versions {
@@ -555,25 +777,33 @@ func (c *DockerConfigurator) createVersions(browserName string, image string, ta
 		Default:  tags[0],
 		Versions: make(map[string]*config.Browser),
 	}
+	entry := c.catalog[browserName]
+	tmpfsSize, shmSize, browserEnv := c.Tmpfs, c.ShmSize, strings.Fields(c.BrowserEnv)
+	if tmpfsSize == 0 {
+		tmpfsSize = entry.Tmpfs
+	}
+	if shmSize == 0 {
+		shmSize = entry.ShmSize
+	}
+	if len(browserEnv) == 0 {
+		browserEnv = entry.Env
+	}
 	for _, tag := range tags {
 		version := tag
 		browser := &config.Browser{
-			Image: imageWithTag(image, tag),
-			Port:  "4444",
-			Path:  "/",
-		}
-		if browserName == firefox || browserName == android || (browserName == opera && version == tag_1216) {
-			browser.Path = "/wd/hub"
+			Image:  imageWithTag(image, tag),
+			Port:   entry.getPort(),
+			Path:   entry.getPath(tag),
+			Labels: entry.getLabels(),
 		}
-		if c.Tmpfs > 0 {
+		if tmpfsSize > 0 {
 			tmpfs := make(map[string]string)
-			tmpfs["/tmp"] = fmt.Sprintf("size=%dm", c.Tmpfs)
+			tmpfs["/tmp"] = fmt.Sprintf("size=%dm", tmpfsSize)
 			browser.Tmpfs = tmpfs
 		}
-		if c.ShmSize > 0 {
-			browser.ShmSize, _ = units.RAMInBytes(fmt.Sprintf("%dm", c.ShmSize))
+		if shmSize > 0 {
+			browser.ShmSize, _ = units.RAMInBytes(fmt.Sprintf("%dm", shmSize))
 		}
-		browserEnv := strings.Fields(c.BrowserEnv)
 		if len(browserEnv) > 0 {
 			browser.Env = browserEnv
 		}
@@ -586,37 +816,13 @@ func imageWithTag(image string, tag string) string {
 	return fmt.Sprintf("%s:%s", image, tag)
 }
 
-func (c *DockerConfigurator) pullImages(image string, tags []string) []string {
-	var pulledTags []string
-	ctx := context.Background()
-	for _, tag := range tags {
-		ref := imageWithTag(image, tag)
-		if !c.pullImage(ctx, ref) {
-			continue
-		}
-		pulledTags = append(pulledTags, tag)
-	}
-	return pulledTags
-}
-
-func (c *DockerConfigurator) pullVideoRecorderImage() {
-	c.Titlef("Pulling video recorder image...")
-	c.pullImage(context.Background(), c.getFullyQualifiedImageRef(videoRecorderImage))
-}
-
-func (c *DockerConfigurator) getFullyQualifiedImageRef(ref string) string {
-	if c.registryHost != "" {
-		return fmt.Sprintf("%s/%s", c.registryHost, ref)
-	}
-	return ref
-}
-
 // JSONMessage defines a message struct from docker.
 type JSONMessage struct {
 	Status          string        `json:"status,omitempty"`
 	Progress        *JSONProgress `json:"progressDetail,omitempty"`
 	ID              string        `json:"id,omitempty"`
 	ProgressMessage string        `json:"progress,omitempty"` //deprecated
+	ErrorMessage    string        `json:"error,omitempty"`
 }
 
 // JSONProgress describes a Progress. terminalFd is the fd of the current terminal,
@@ -633,60 +839,115 @@ type JSONProgress struct {
 	Units      string `json:"units,omitempty"`
 }
 
-func (c *DockerConfigurator) pullImage(ctx context.Context, ref string) bool {
-	c.Pointf("Pulling image %v", color.BlueString(ref))
+// pullImage pulls image retrying transient failures, the final error is also reported to output
+func (c *DockerConfigurator) pullImage(ctx context.Context, ref string) error {
+	return c.pullImages(ctx, []string{ref})[ref]
+}
+
+// pullImages pulls images with at most c.Parallel concurrent workers showing progress of every image in a separate block
+// and returns errors of failed pulls by image reference
+func (c *DockerConfigurator) pullImages(ctx context.Context, refs []string) map[string]error {
+	var out io.Writer = colorable.NewColorableStdout()
+	if c.Quiet {
+		out = io.Discard
+	}
+	progress := rewriter.NewProgress(out)
+	for _, ref := range refs {
+		progress.Start(color.BlueString(ref), "waiting")
+	}
+	// Credentials are resolved before starting workers as they are memoized without locking
+	pullOptions := make([]image.PullOptions, len(refs))
+	for i, ref := range refs {
+		pullOptions[i] = c.getPullOptions(ref)
+	}
+	errs := make(map[string]error)
+	var mu sync.Mutex
+	forEachParallel(c.Parallel, len(refs), func(i int) {
+		ref := refs[i]
+		name := color.BlueString(ref)
+		progress.Start(name, "pulling")
+		err := c.retryNotify(ctx, func(msg string) {
+			progress.Start(name, color.YellowString(msg))
+		}, "pull", func() error {
+			return c.pullImageOnce(ctx, ref, pullOptions[i], func(id string, line string) {
+				progress.Update(name, id, line)
+			})
+		})
+		if err != nil {
+			progress.Finish(name, color.RedString("failed"))
+			mu.Lock()
+			errs[ref] = err
+			mu.Unlock()
+			return
+		}
+		progress.Finish(name, color.GreenString("pulled"))
+	})
+	for _, ref := range refs {
+		if err, ok := errs[ref]; ok {
+			c.Errorf(`Failed to pull image "%s": %v`, ref, color.RedString("%v", err))
+		}
+	}
+	return errs
+}
+
+// getPullOptions returns options with credentials of image registry
+func (c *DockerConfigurator) getPullOptions(ref string) image.PullOptions {
 	pullOptions := image.PullOptions{}
-	if c.authConfig != nil {
-		buf, err := json.Marshal(c.authConfig)
+	if authConfig := c.getAuthConfig(imageRegistryHost(ref)); authConfig != nil {
+		buf, err := json.Marshal(authConfig)
 		if err != nil {
 			c.Errorf("Failed to prepare registry authentication config: %v", err)
 		} else {
 			pullOptions.RegistryAuth = base64.URLEncoding.EncodeToString(buf)
 		}
 	}
+	return pullOptions
+}
+
+// pullImageOnce pulls image reporting progress of every layer, empty line means that layer is complete
+func (c *DockerConfigurator) pullImageOnce(ctx context.Context, ref string, pullOptions image.PullOptions, onProgress func(id string, line string)) error {
 	resp, err := c.docker.ImagePull(ctx, ref, pullOptions)
 	if err != nil {
-		c.Errorf(`Failed to pull image "%s": %v`, ref, err)
-		return false
+		if errdefs.IsNotFound(err) || errdefs.IsUnauthorized(err) || errdefs.IsInvalidParameter(err) {
+			return permanent(err)
+		}
+		return err
 	}
 	defer resp.Close()
 
 	var row JSONMessage
 
 	scanner := bufio.NewScanner(resp)
-	writer := rewriter.New(colorable.NewColorableStdout())
 
 	for _ = ""; scanner.Scan(); {
+		row = JSONMessage{}
 		err := json.Unmarshal(scanner.Bytes(), &row)
 		if err != nil {
-			return false
+			return fmt.Errorf("invalid pull response: %v", err)
+		}
+		if row.ErrorMessage != "" {
+			return errors.New(row.ErrorMessage)
 		}
 
 		select {
 		case <-ctx.Done():
 			{
-				c.Errorf(`Pulling "%s" interrupted: %v`, ref, ctx.Err())
-				return false
+				return permanent(fmt.Errorf("pulling interrupted: %v", ctx.Err()))
 			}
 		default:
 			{
-				if row.Progress != nil {
+				if row.Progress != nil && row.ID != "" {
 					if row.Progress.Current != row.Progress.Total {
-						_, _ = fmt.Fprintf(writer, "\t[%s]: %s %s\n", row.ID, row.Status, row.ProgressMessage)
+						onProgress(row.ID, fmt.Sprintf("%s %s", row.Status, row.ProgressMessage))
 					} else {
-						_, _ = fmt.Fprint(writer, "\r")
+						onProgress(row.ID, "")
 					}
 				}
-
-				_ = writer.Flush()
 			}
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		c.Errorf(`Failed to pull image "%s": %v`, ref, color.RedString("%v", err))
-	}
-	return true
+	return scanner.Err()
 }
 
 func (c *DockerConfigurator) IsRunning() bool {
@@ -694,7 +955,7 @@ func (c *DockerConfigurator) IsRunning() bool {
 }
 
 func (c *DockerConfigurator) getSelenoidContainer() *types.Container {
-	return c.getContainer(selenoidContainerName)
+	return c.getContainer(c.instanceName(selenoidContainerName))
 }
 
 func (c *DockerConfigurator) IsUIRunning() bool {
@@ -702,7 +963,7 @@ func (c *DockerConfigurator) IsUIRunning() bool {
 }
 
 func (c *DockerConfigurator) getSelenoidUIContainer() *types.Container {
-	return c.getContainer(selenoidUIContainerName)
+	return c.getContainer(c.instanceName(selenoidUIContainerName))
 }
 
 func (c *DockerConfigurator) getContainer(name string) *types.Container {
@@ -718,45 +979,97 @@ func (c *DockerConfigurator) getContainer(name string) *types.Container {
 	return nil
 }
 
-func (c *DockerConfigurator) PrintArgs() error {
+func (c *DockerConfigurator) Logs(ctx context.Context, w io.Writer, opts *LogsOptions) error {
+	sc := c.getSelenoidContainer()
+	if sc == nil {
+		return errors.New("Selenoid container is not running")
+	}
+	return c.containerLogs(ctx, sc.ID, w, opts)
+}
+
+func (c *DockerConfigurator) UILogs(ctx context.Context, w io.Writer, opts *LogsOptions) error {
+	uc := c.getSelenoidUIContainer()
+	if uc == nil {
+		return errors.New("Selenoid UI container is not running")
+	}
+	return c.containerLogs(ctx, uc.ID, w, opts)
+}
+
+func (c *DockerConfigurator) containerLogs(ctx context.Context, id string, w io.Writer, opts *LogsOptions) error {
+	info, err := c.docker.ContainerInspect(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to inspect container: %v", err)
+	}
+	tail := opts.Tail
+	if tail == "" {
+		tail = allLines
+	}
+	r, err := c.docker.ContainerLogs(ctx, id, container.LogsOptions{
+		ShowStdout: true,
+		ShowStderr: true,
+		Follow:     opts.Follow,
+		Since:      opts.Since,
+		Tail:       tail,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read container logs: %v", err)
+	}
+	defer r.Close()
+	if info.Config != nil && info.Config.Tty {
+		_, err = io.Copy(w, r)
+	} else {
+		_, err = stdcopy.StdCopy(w, w, r)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read container logs: %v", err)
+	}
+	return nil
+}
+
+func (c *DockerConfigurator) PrintArgs(ctx context.Context) error {
 	img := c.getSelenoidImage()
 	if img == nil {
 		return errors.New("Selenoid image is not downloaded: this is probably a bug")
 	}
 	cfg := &containerConfig{
-		Image:     img,
+		Image:     img.RepoTags[0],
 		Cmd:       []string{"--help"},
 		PrintLogs: true,
 	}
-	return c.startContainer(cfg)
+	return c.startContainer(ctx, cfg)
 }
 
 const (
-	videoDirName = "video"
-	logsDirName  = "logs"
-	networkName  = "selenoid"
+	videoDirName  = "video"
+	logsDirName   = "logs"
+	networkName   = "selenoid"
+	serviceLabel  = "com.aerokube.cm.service"
+	instanceLabel = "com.aerokube.cm.instance"
 )
 
-func (c *DockerConfigurator) Start() error {
+func (c *DockerConfigurator) Start(ctx context.Context) error {
 	img := c.getSelenoidImage()
 	if img == nil {
 		return errors.New("selenoid image is not downloaded: this is probably a bug")
 	}
 
-	volumeConfigDir := getVolumeConfigDir(c.ConfigDir, selenoidConfigDirElem)
-	videoConfigDir := getVolumeConfigDir(filepath.Join(c.ConfigDir, videoDirName), append(selenoidConfigDirElem, videoDirName))
-	logsConfigDir := getVolumeConfigDir(filepath.Join(c.ConfigDir, logsDirName), append(selenoidConfigDirElem, logsDirName))
+	configDirElem := c.selenoidConfigDirElem()
+	volumeConfigDir := getVolumeConfigDir(c.ConfigDir, configDirElem)
+	videoConfigDir := getVolumeConfigDir(filepath.Join(c.ConfigDir, videoDirName), append(configDirElem, videoDirName))
+	logsConfigDir := getVolumeConfigDir(filepath.Join(c.ConfigDir, logsDirName), append(configDirElem, logsDirName))
+	cfg := c.getSelenoidContainerConfig(img.RepoTags[0], volumeConfigDir, videoConfigDir, logsConfigDir, c.getSocketVolume())
+	return c.startContainer(ctx, cfg)
+}
+
+// getSelenoidContainerConfig returns Selenoid container definition for given image and host directories
+func (c *DockerConfigurator) getSelenoidContainerConfig(imageRef string, volumeConfigDir string, videoConfigDir string, logsConfigDir string, socketVolume string) *containerConfig {
 	volumes := []string{
 		fmt.Sprintf("%s:/etc/selenoid:ro,Z", volumeConfigDir),
 		fmt.Sprintf("%s:/opt/selenoid/video:Z", videoConfigDir),
 		fmt.Sprintf("%s:/opt/selenoid/logs:Z", logsConfigDir),
 	}
-	const dockerSocket = "/var/run/docker.sock"
-	if isWindows() {
-		//With two slashes. See https://stackoverflow.com/questions/36765138/bind-to-docker-socket-on-windows
-		volumes = append(volumes, fmt.Sprintf("/%s:%s", dockerSocket, dockerSocket))
-	} else if fileExists(dockerSocket) {
-		volumes = append(volumes, fmt.Sprintf("%s:%s:Z", dockerSocket, dockerSocket))
+	if socketVolume != "" {
+		volumes = append(volumes, socketVolume)
 	}
 
 	cmd := []string{}
@@ -771,13 +1084,14 @@ func (c *DockerConfigurator) Start() error {
 		cmd = append(cmd, "-video-output-dir", "/opt/selenoid/video/")
 	}
 	if !contains(cmd, "-video-recorder-image") && isVideoRecordingSupported(c.Logger, c.Version) {
-		cmd = append(cmd, "-video-recorder-image", c.getFullyQualifiedImageRef(videoRecorderImage))
+		cmd = append(cmd, "-video-recorder-image", c.selenoidRegistry.imageRef(videoRecorderImage))
 	}
 	if !c.DisableLogs && !contains(cmd, "-log-output-dir") && isLogSavingSupported(c.Logger, c.Version) {
 		cmd = append(cmd, "-log-output-dir", "/opt/selenoid/logs/")
 	}
+	network := c.instanceName(networkName)
 	if !contains(cmd, "-container-network") {
-		cmd = append(cmd, "-container-network", networkName)
+		cmd = append(cmd, "-container-network", network)
 	}
 
 	overrideEnv := strings.Fields(c.Env)
@@ -785,17 +1099,39 @@ func (c *DockerConfigurator) Start() error {
 		overrideEnv = append(overrideEnv, fmt.Sprintf("OVERRIDE_VIDEO_OUTPUT_DIR=%s", videoConfigDir))
 	}
 	cfg := &containerConfig{
-		Name:        selenoidContainerName,
-		Image:       img,
+		Name:        c.instanceName(selenoidContainerName),
+		Service:     ServiceSelenoid,
+		Image:       imageRef,
 		HostPort:    c.Port,
 		ServicePort: DefaultPort,
 		Volumes:     volumes,
-		Network:     networkName,
+		Network:     network,
 		Cmd:         cmd,
 		OverrideEnv: overrideEnv,
 		UserNS:      c.UserNS,
 	}
-	return c.startContainer(cfg)
+	if c.runtime == ModePodman {
+		// Relabeling Podman socket is not possible, so SELinux separation is disabled to let Selenoid access it
+		cfg.SecurityOpt = []string{"label=disable"}
+	}
+	return cfg
+}
+
+// getSocketVolume returns a bind exposing container engine API to Selenoid as Docker socket
+func (c *DockerConfigurator) getSocketVolume() string {
+	if c.runtime == ModePodman {
+		if c.hostSocket != "" && fileExists(c.hostSocket) {
+			return fmt.Sprintf("%s:%s", c.hostSocket, dockerSocket)
+		}
+		return ""
+	}
+	if isWindows() {
+		//With two slashes. See https://stackoverflow.com/questions/36765138/bind-to-docker-socket-on-windows
+		return fmt.Sprintf("/%s:%s", dockerSocket, dockerSocket)
+	} else if fileExists(c.hostSocket) {
+		return fmt.Sprintf("%s:%s:Z", c.hostSocket, dockerSocket)
+	}
+	return ""
 }
 
 func isVideoRecordingSupported(logger Logger, version string) bool {
@@ -855,65 +1191,71 @@ func chooseVolumeConfigDir(defaultConfigDir string, elem []string) string {
 	return defaultConfigDir
 }
 
-func (c *DockerConfigurator) PrintUIArgs() error {
+func (c *DockerConfigurator) PrintUIArgs(ctx context.Context) error {
 	img := c.getSelenoidUIImage()
 	if img == nil {
 		return errors.New("selenoid UI image is not downloaded: this is probably a bug")
 	}
 	cfg := &containerConfig{
-		Image:     img,
+		Image:     img.RepoTags[0],
 		Cmd:       []string{"--help"},
 		PrintLogs: true,
 	}
-	return c.startContainer(cfg)
+	return c.startContainer(ctx, cfg)
 }
 
-func (c *DockerConfigurator) StartUI() error {
+func (c *DockerConfigurator) StartUI(ctx context.Context) error {
 	img := c.getSelenoidUIImage()
 	if img == nil {
 		return errors.New("selenoid ui image is not downloaded: this is probably a bug")
 	}
 
-	var cmd, candidates []string
+	var candidates []string
 	var selenoidUri string
 containers:
 	for _, containerName := range []string{
-		selenoidContainerName, ggrUIContainerName,
+		c.instanceName(selenoidContainerName), ggrUIContainerName,
 	} {
 		if ctr := c.getContainer(containerName); ctr != nil {
 			for _, p := range ctr.Ports {
 				if p.PublicPort != 0 {
-					selenoidUri = fmt.Sprintf("--selenoid-uri=http://%s:%d", containerName, p.PublicPort)
+					selenoidUri = fmt.Sprintf("http://%s:%d", containerName, p.PublicPort)
 					candidates = []string{containerName}
 					break containers
 				}
 			}
 		}
 	}
+	if len(candidates) == 0 {
+		c.Errorf("Neither Selenoid nor Ggr UI is started. Selenoid UI may not work.")
+	}
+	cfg := c.getSelenoidUIContainerConfig(img.RepoTags[0], selenoidUri)
+	return c.startContainer(ctx, cfg)
+}
+
+// getSelenoidUIContainerConfig returns Selenoid UI container definition for given image and Selenoid URI
+func (c *DockerConfigurator) getSelenoidUIContainerConfig(imageRef string, selenoidUri string) *containerConfig {
+	var cmd []string
 	overrideCmd := strings.Fields(c.Args)
 	if len(overrideCmd) > 0 {
 		cmd = overrideCmd
 	}
-	if !contains(cmd, "--selenoid-uri") {
-		cmd = append(cmd, selenoidUri)
-	}
-
-	if len(candidates) == 0 {
-		c.Errorf("Neither Selenoid nor Ggr UI is started. Selenoid UI may not work.")
+	if !contains(cmd, "--selenoid-uri") && selenoidUri != "" {
+		cmd = append(cmd, fmt.Sprintf("--selenoid-uri=%s", selenoidUri))
 	}
 
 	overrideEnv := strings.Fields(c.Env)
-	cfg := &containerConfig{
-		Name:        selenoidUIContainerName,
-		Image:       img,
+	return &containerConfig{
+		Name:        c.instanceName(selenoidUIContainerName),
+		Service:     ServiceSelenoidUI,
+		Image:       imageRef,
 		HostPort:    c.Port,
 		ServicePort: UIDefaultPort,
-		Network:     networkName,
+		Network:     c.instanceName(networkName),
 		Cmd:         cmd,
 		OverrideEnv: overrideEnv,
 		UserNS:      c.UserNS,
 	}
-	return c.startContainer(cfg)
 }
 
 func validateEnviron(envs []string) []string {
@@ -929,7 +1271,8 @@ func validateEnviron(envs []string) []string {
 
 type containerConfig struct {
 	Name        string
-	Image       *image.Summary
+	Service     string
+	Image       string
 	HostPort    int
 	ServicePort int
 	Volumes     []string
@@ -937,12 +1280,13 @@ type containerConfig struct {
 	Cmd         []string
 	OverrideEnv []string
 	UserNS      string
+	SecurityOpt []string
 	PrintLogs   bool
 }
 
-func (c *DockerConfigurator) startContainer(cfg *containerConfig) error {
-	ctx := context.Background()
-	env := validateEnviron(os.Environ())
+// getContainerEnv returns container environment: host environment variables are only passed when hostEnv is set
+func (c *DockerConfigurator) getContainerEnv(cfg *containerConfig, hostEnv []string) []string {
+	env := validateEnviron(hostEnv)
 	env = append(env, fmt.Sprintf("TZ=%s", time.Local))
 	if len(cfg.OverrideEnv) > 0 {
 		env = cfg.OverrideEnv
@@ -950,21 +1294,35 @@ func (c *DockerConfigurator) startContainer(cfg *containerConfig) error {
 	if !contains(env, dockerApiVersion) {
 		env = append(env, fmt.Sprintf("%s=%s", dockerApiVersion, c.docker.ClientVersion()))
 	}
+	return env
+}
+
+// startContainer creates and starts container, container is removed when it fails to start or operation is cancelled
+func (c *DockerConfigurator) startContainer(ctx context.Context, cfg *containerConfig) error {
+	env := c.getContainerEnv(cfg, os.Environ())
 	servicePortString := strconv.Itoa(cfg.ServicePort)
 	port, err := nat.NewPort("tcp", servicePortString)
 	if err != nil {
 		return fmt.Errorf("failed to init port: %v", err)
 	}
 
-	err = c.createNetworkIfNeeded(cfg.Network)
-	if err != nil {
-		return fmt.Errorf("failed to configure container network: %v", err)
+	if cfg.Network != "" {
+		err = c.createNetworkIfNeeded(ctx, cfg.Network)
+		if err != nil {
+			return fmt.Errorf("failed to configure container network: %v", err)
+		}
 	}
 	containerConfig := container.Config{
 		Hostname: "localhost",
-		Image:    cfg.Image.RepoTags[0],
+		Image:    cfg.Image,
 		Env:      env,
 	}
+	if cfg.Service != "" {
+		containerConfig.Labels = map[string]string{
+			serviceLabel:  cfg.Service,
+			instanceLabel: c.Instance,
+		}
+	}
 	if cfg.ServicePort > 0 {
 		containerConfig.ExposedPorts = map[nat.Port]struct{}{port: {}}
 	}
@@ -973,7 +1331,8 @@ func (c *DockerConfigurator) startContainer(cfg *containerConfig) error {
 	}
 	hostConfig := container.HostConfig{
 		Binds:       cfg.Volumes,
-		NetworkMode: networkName,
+		NetworkMode: container.NetworkMode(cfg.Network),
+		SecurityOpt: cfg.SecurityOpt,
 	}
 	if cfg.UserNS != "" {
 		mode := container.UsernsMode(cfg.UserNS)
@@ -1003,12 +1362,15 @@ func (c *DockerConfigurator) startContainer(cfg *containerConfig) error {
 		return fmt.Errorf("failed to create container: %v", err)
 	}
 	err = c.docker.ContainerStart(ctx, ctr.ID, container.StartOptions{})
+	if err == nil {
+		err = ctx.Err()
+	}
 	if err != nil {
-		_ = c.removeContainer(ctr.ID)
+		_ = c.removeContainer(context.WithoutCancel(ctx), ctr.ID)
 		return fmt.Errorf("failed to start container: %v", err)
 	}
 	if cfg.PrintLogs {
-		defer c.removeContainer(ctr.ID)
+		defer c.removeContainer(context.WithoutCancel(ctx), ctr.ID)
 		r, err := c.docker.ContainerLogs(ctx, ctr.ID, container.LogsOptions{
 			ShowStdout: true,
 			ShowStderr: true,
@@ -1022,8 +1384,7 @@ func (c *DockerConfigurator) startContainer(cfg *containerConfig) error {
 	return nil
 }
 
-func (c *DockerConfigurator) createNetworkIfNeeded(networkName string) error {
-	ctx := context.Background()
+func (c *DockerConfigurator) createNetworkIfNeeded(ctx context.Context, networkName string) error {
 	_, err := c.docker.NetworkInspect(ctx, networkName, types.NetworkInspectOptions{})
 	if err != nil {
 		_, err = c.docker.NetworkCreate(ctx, networkName, types.NetworkCreate{})
@@ -1034,8 +1395,7 @@ func (c *DockerConfigurator) createNetworkIfNeeded(networkName string) error {
 	return nil
 }
 
-func (c *DockerConfigurator) removeContainer(id string) error {
-	ctx := context.Background()
+func (c *DockerConfigurator) removeContainer(ctx context.Context, id string) error {
 	if c.Graceful {
 		timeout := int(c.GracefulTimeout.Milliseconds() / 1000)
 		err := c.docker.ContainerStop(ctx, id, container.StopOptions{Timeout: &timeout})
@@ -1047,10 +1407,10 @@ func (c *DockerConfigurator) removeContainer(id string) error {
 	return c.docker.ContainerRemove(ctx, id, container.RemoveOptions{RemoveVolumes: true, Force: true})
 }
 
-func (c *DockerConfigurator) Stop() error {
+func (c *DockerConfigurator) Stop(ctx context.Context) error {
 	sc := c.getSelenoidContainer()
 	if sc != nil {
-		err := c.removeContainer(sc.ID)
+		err := c.removeContainer(ctx, sc.ID)
 		if err != nil {
 			return fmt.Errorf("failed to stop Selenoid container: %v", err)
 		}
@@ -1058,10 +1418,10 @@ func (c *DockerConfigurator) Stop() error {
 	return nil
 }
 
-func (c *DockerConfigurator) StopUI() error {
+func (c *DockerConfigurator) StopUI(ctx context.Context) error {
 	uc := c.getSelenoidUIContainer()
 	if uc != nil {
-		err := c.removeContainer(uc.ID)
+		err := c.removeContainer(ctx, uc.ID)
 		if err != nil {
 			return fmt.Errorf("failed to stop Selenoid UI container: %v", err)
 		}
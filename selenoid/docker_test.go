@@ -1,20 +1,33 @@
 package selenoid
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/aerokube/selenoid/config"
 	"github.com/docker/docker/api/types/image"
 	assert "github.com/stretchr/testify/require"
 )
 
+const (
+	flakyImage  = "selenoid/flaky"
+	brokenImage = "selenoid/broken"
+)
+
 var (
+	pullAttempts     = make(map[string]int)
+	pullAttemptsLock sync.Mutex
 	mockDockerServer *httptest.Server
 	imageName        string
 	containerName    string
@@ -105,6 +118,28 @@ func mux() http.Handler {
 		},
 	))
 
+	mux.HandleFunc("/v2/browsers/playwright-chromium/tags/list", http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Content-Type", "application/json")
+			_, _ = fmt.Fprintln(w, `{"name":"playwright-chromium", "tags": ["1.41.2", "1.42.1", "latest"]}`)
+		},
+	))
+
+	mux.HandleFunc("/v2/selenoid/opera/manifests/44.0", http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+			_, _ = fmt.Fprintln(w, `{
+				"schemaVersion": 2,
+				"mediaType": "application/vnd.docker.distribution.manifest.v2+json",
+				"config": {"mediaType": "application/vnd.docker.container.image.v1+json", "size": 1000, "digest": "sha256:b5b2b2c507a0944348e0303114d8d93aaaa081732b86451d9bce1f432a537bc7"},
+				"layers": [
+					{"mediaType": "application/vnd.docker.image.rootfs.diff.tar.gzip", "size": 30000, "digest": "sha256:e692418e4cbaf90ca69d05a66403747baa33ee08806650b51fab815ad7fc331f"},
+					{"mediaType": "application/vnd.docker.image.rootfs.diff.tar.gzip", "size": 20000, "digest": "sha256:3c3a4604a545cdc127456d94e421cd355bca5b528f4a9c1905b15da2eb4a4c6b"}
+				]
+			}`)
+		},
+	))
+
 	//Docker API mock
 	mux.HandleFunc("/v1.29/version", http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
@@ -130,6 +165,21 @@ func mux() http.Handler {
 	))
 	mux.HandleFunc("/v1.29/images/create", http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
+			ref := r.URL.Query().Get("fromImage") + ":" + r.URL.Query().Get("tag")
+			pullAttemptsLock.Lock()
+			pullAttempts[ref]++
+			attempt := pullAttempts[ref]
+			pullAttemptsLock.Unlock()
+			switch {
+			case strings.HasSuffix(r.URL.Query().Get("fromImage"), flakyImage) && attempt == 1:
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(`{"message": "temporary failure"}`))
+				return
+			case strings.HasSuffix(r.URL.Query().Get("fromImage"), brokenImage):
+				w.WriteHeader(http.StatusOK)
+				_, _ = w.Write([]byte(`{"error": "unexpected EOF"}`))
+				return
+			}
 			w.WriteHeader(http.StatusOK)
 			output := `{"id": "a86cd3433934", "status": "Downloading layer"}`
 			_, _ = w.Write([]byte(output))
@@ -157,6 +207,23 @@ func mux() http.Handler {
 			_, _ = w.Write([]byte(output))
 		},
 	))
+	mux.HandleFunc("/v1.29/images/get", http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			_, _ = fmt.Fprintf(w, "saved:%s", strings.Join(r.URL.Query()["names"], ","))
+		},
+	))
+	mux.HandleFunc("/v1.29/images/load", http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			data, _ := io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusOK)
+			if !strings.HasPrefix(string(data), "saved:") {
+				_, _ = w.Write([]byte(`{"errorDetail": {"message": "unexpected images archive"}, "error": "unexpected images archive"}`))
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"stream": "Loaded image: %s\n"}`, string(data))
+		},
+	))
 	mux.HandleFunc("/v1.29/networks/selenoid", http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
@@ -222,6 +289,13 @@ func mux() http.Handler {
 			_, _ = w.Write([]byte("Some logs...\n"))
 		},
 	))
+	mux.HandleFunc("/v1.29/containers/e90e34656806/json", http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			output := `{"Id": "e90e34656806", "Config": {"Tty": true}}`
+			_, _ = w.Write([]byte(output))
+		},
+	))
 	mux.HandleFunc("/v1.29/containers/e90e34656806", http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusNoContent)
@@ -279,13 +353,91 @@ func TestFetchImageTags(t *testing.T) {
 	c, err := NewDockerConfigurator(&lcConfig)
 	assert.NoError(t, err)
 	defer c.Close()
-	tags := c.fetchImageTags("selenoid/firefox")
+	tags, err := c.fetchImageTags(context.Background(), c.browsersRegistry, "selenoid/firefox")
+	assert.NoError(t, err)
 	assert.Len(t, tags, 3)
 	assert.Equal(t, tags[0], "46.0")
 	assert.Equal(t, tags[1], "45.0")
 	assert.Equal(t, tags[2], "7.0")
 }
 
+func TestRegistryErrorsRetries(t *testing.T) {
+	var lock sync.Mutex
+	requests := make(map[string]int)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		requests[r.URL.Path]++
+		lock.Unlock()
+		switch {
+		case r.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case strings.HasPrefix(r.URL.Path, "/v2/selenoid/flaky/"):
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case strings.HasPrefix(r.URL.Path, "/v2/selenoid/private/"):
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	c, err := NewDockerConfigurator(&LifecycleConfig{
+		RegistryUrl:  srv.URL,
+		Retries:      2,
+		RetryBackoff: time.Millisecond,
+		Quiet:        true,
+	})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	_, err = c.fetchImageTags(context.Background(), c.browsersRegistry, "selenoid/missing")
+	assert.Error(t, err)
+	_, err = c.fetchImageTags(context.Background(), c.browsersRegistry, "selenoid/private")
+	assert.Error(t, err)
+	_, err = c.fetchImageTags(context.Background(), c.browsersRegistry, "selenoid/flaky")
+	assert.Error(t, err)
+	_, err = c.fetchImageSize(context.Background(), c.browsersRegistry.imageRef("selenoid/missing:1.0"))
+	assert.Error(t, err)
+	_, err = c.fetchImageSize(context.Background(), c.browsersRegistry.imageRef("selenoid/flaky:1.0"))
+	assert.Error(t, err)
+
+	assert.Equal(t, 1, requests["/v2/selenoid/missing/tags/list"])
+	assert.Equal(t, 1, requests["/v2/selenoid/private/tags/list"])
+	assert.Equal(t, 3, requests["/v2/selenoid/flaky/tags/list"])
+	assert.Equal(t, 1, requests["/v2/selenoid/missing/manifests/1.0"])
+	assert.Equal(t, 3, requests["/v2/selenoid/flaky/manifests/1.0"])
+}
+
+func TestRegistryUnavailablePingedOnce(t *testing.T) {
+	var lock sync.Mutex
+	pings := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		if r.URL.Path == "/v2/" {
+			pings++
+		}
+		lock.Unlock()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	c, err := NewDockerConfigurator(&LifecycleConfig{
+		RegistryUrl: srv.URL,
+		Quiet:       true,
+	})
+	assert.NoError(t, err)
+	defer c.Close()
+
+	fetchErrors := make([]error, 4)
+	forEachParallel(len(fetchErrors), len(fetchErrors), func(i int) {
+		_, fetchErrors[i] = c.fetchImageTags(context.Background(), c.browsersRegistry, "selenoid/firefox")
+	})
+	for _, err := range fetchErrors {
+		assert.Error(t, err)
+	}
+	assert.Equal(t, 1, pings)
+}
+
 func TestPullImages(t *testing.T) {
 	lcConfig := LifecycleConfig{
 		RegistryUrl: mockDockerServer.URL,
@@ -294,21 +446,58 @@ func TestPullImages(t *testing.T) {
 	c, err := NewDockerConfigurator(&lcConfig)
 	assert.NoError(t, err)
 	defer c.Close()
-	tags := c.pullImages("selenoid/firefox", []string{"46.0", "45.0"})
-	assert.Len(t, tags, 2)
-	assert.Equal(t, tags[0], "46.0")
-	assert.Equal(t, tags[1], "45.0")
+	errs := c.pullImages(context.Background(), []string{"selenoid/firefox:46.0", "selenoid/firefox:45.0"})
+	assert.Empty(t, errs)
+}
+
+func TestPullImagesWithRetries(t *testing.T) {
+	lcConfig := LifecycleConfig{
+		RegistryUrl:  mockDockerServer.URL,
+		Retries:      2,
+		RetryBackoff: time.Millisecond,
+	}
+	c, err := NewDockerConfigurator(&lcConfig)
+	assert.NoError(t, err)
+	defer c.Close()
+	errs := c.pullImages(context.Background(), []string{imageWithTag(flakyImage, "1.0"), imageWithTag(brokenImage, "1.0")})
+	assert.Len(t, errs, 1)
+	assert.Error(t, errs[imageWithTag(brokenImage, "1.0")])
+	assert.Equal(t, 3, pullAttempts[brokenImage+":1.0"])
+
+	c.Retries = 0
+	err = c.pullImage(context.Background(), imageWithTag(flakyImage, "2.0"))
+	assert.Error(t, err)
 }
 
 func TestConfigureDocker(t *testing.T) {
-	testConfigure(t, true)
+	testConfigure(t, true, 1)
+}
+
+func TestConfigureDockerParallel(t *testing.T) {
+	testConfigure(t, true, DefaultParallel)
+}
+
+func TestConfigureDockerUnsupportedBrowser(t *testing.T) {
+	withTmpDir(t, "test-docker-configure", func(t *testing.T, dir string) {
+		c, err := NewDockerConfigurator(&LifecycleConfig{
+			ConfigDir:   dir,
+			RegistryUrl: mockDockerServer.URL,
+			Browsers:    "opera;safari;netscape",
+			Quiet:       true,
+		})
+		assert.NoError(t, err)
+		defer c.Close()
+		_, err = c.Configure(context.Background())
+		assert.EqualError(t, err, "unsupported browsers: netscape, safari")
+		assert.False(t, c.IsConfigured())
+	})
 }
 
 func TestLimitNoPull(t *testing.T) {
-	testConfigure(t, false)
+	testConfigure(t, false, 1)
 }
 
-func testConfigure(t *testing.T, download bool) {
+func testConfigure(t *testing.T, download bool, parallel int) {
 	withTmpDir(t, "test-docker-configure", func(t *testing.T, dir string) {
 
 		lcConfig := LifecycleConfig{
@@ -317,6 +506,7 @@ func testConfigure(t *testing.T, download bool) {
 			Download:     download,
 			Quiet:        false,
 			LastVersions: 2,
+			Parallel:     parallel,
 			Tmpfs:        512,
 			ShmSize:      256,
 			Browsers:     "firefox:>45.0;opera;android;MicrosoftEdge",
@@ -329,7 +519,7 @@ func testConfigure(t *testing.T, download bool) {
 		assert.NoError(t, err)
 		defer c.Close()
 		assert.False(t, c.IsConfigured())
-		cfgPointer, err := (*c).Configure()
+		cfgPointer, err := (*c).Configure(context.Background())
 		assert.NoError(t, err)
 		assert.NotNil(t, cfgPointer)
 
@@ -345,7 +535,7 @@ func testConfigure(t *testing.T, download bool) {
 
 		correctFFBrowsers := make(map[string]*config.Browser)
 		correctFFBrowsers["46.0"] = &config.Browser{
-			Image:   c.getFullyQualifiedImageRef("selenoid/firefox:46.0"),
+			Image:   c.browsersRegistry.imageRef("selenoid/firefox:46.0"),
 			Port:    "4444",
 			Path:    "/wd/hub",
 			Tmpfs:   tmpfsMap,
@@ -364,7 +554,7 @@ func testConfigure(t *testing.T, download bool) {
 
 		correctOperaBrowsers := make(map[string]*config.Browser)
 		correctOperaBrowsers["44.0"] = &config.Browser{
-			Image:   c.getFullyQualifiedImageRef("selenoid/opera:44.0"),
+			Image:   c.browsersRegistry.imageRef("selenoid/opera:44.0"),
 			Port:    "4444",
 			Path:    "/",
 			Tmpfs:   tmpfsMap,
@@ -382,7 +572,7 @@ func testConfigure(t *testing.T, download bool) {
 
 		correctAndroidBrowsers := make(map[string]*config.Browser)
 		correctAndroidBrowsers["10.0"] = &config.Browser{
-			Image:   c.getFullyQualifiedImageRef("selenoid/android:10.0"),
+			Image:   c.browsersRegistry.imageRef("selenoid/android:10.0"),
 			Port:    "4444",
 			Path:    "/wd/hub",
 			Tmpfs:   tmpfsMap,
@@ -400,7 +590,7 @@ func testConfigure(t *testing.T, download bool) {
 
 		correctEdgeBrowsers := make(map[string]*config.Browser)
 		correctEdgeBrowsers["88.0"] = &config.Browser{
-			Image:   c.getFullyQualifiedImageRef("browsers/edge:88.0"),
+			Image:   c.browsersRegistry.imageRef("browsers/edge:88.0"),
 			Port:    "4444",
 			Path:    "/",
 			Tmpfs:   tmpfsMap,
@@ -444,7 +634,7 @@ func TestSyncWithConfig(t *testing.T) {
 		assert.NoError(t, err)
 		defer c.Close()
 		assert.False(t, c.IsConfigured())
-		cfgPointer, err := (*c).Configure()
+		cfgPointer, err := (*c).Configure(context.Background())
 		assert.NoError(t, err)
 		assert.NotNil(t, cfgPointer)
 
@@ -463,9 +653,19 @@ func TestStartStopContainer(t *testing.T) {
 	})
 	assert.NoError(t, err)
 	assert.True(t, c.IsRunning())
-	assert.NoError(t, c.Start())
-	c.Status()
-	assert.NoError(t, c.Stop())
+	assert.NoError(t, c.Start(context.Background()))
+	status := c.Status()
+	assert.Equal(t, ModeDocker, status.Mode)
+	assert.True(t, status.Downloaded)
+	assert.Equal(t, "docker.io/aerokube/selenoid:latest", status.ImageRef)
+	assert.Equal(t, Latest, status.Version)
+	assert.True(t, status.Running)
+	assert.Equal(t, "e90e34656806", status.ContainerID)
+	assert.Equal(t, DefaultPort, status.Port)
+	var logs bytes.Buffer
+	assert.NoError(t, c.Logs(context.Background(), &logs, &LogsOptions{Tail: "10"}))
+	assert.Equal(t, "Some logs...\n", logs.String())
+	assert.NoError(t, c.Stop(context.Background()))
 }
 
 func TestStartStopUIContainer(t *testing.T) {
@@ -483,9 +683,42 @@ func TestStartStopUIContainer(t *testing.T) {
 	setImageName(selenoidUIImage)
 	setPort(UIDefaultPort)
 	assert.True(t, c.IsUIRunning())
-	assert.NoError(t, c.StartUI())
-	c.UIStatus()
-	assert.NoError(t, c.StopUI())
+	assert.NoError(t, c.StartUI(context.Background()))
+	uiStatus := c.UIStatus()
+	assert.Equal(t, ServiceSelenoidUI, uiStatus.Service)
+	assert.True(t, uiStatus.Running)
+	assert.Equal(t, selenoidUIContainerName, uiStatus.ContainerName)
+	assert.Equal(t, UIDefaultPort, uiStatus.Port)
+	assert.NoError(t, c.UILogs(context.Background(), io.Discard, &LogsOptions{}))
+	assert.NoError(t, c.StopUI(context.Background()))
+}
+
+func TestListContainers(t *testing.T) {
+	c, err := NewDockerConfigurator(&LifecycleConfig{
+		RegistryUrl: mockDockerServer.URL,
+		Quiet:       true,
+	})
+	assert.NoError(t, err)
+	defer c.Close()
+	statuses, err := c.List()
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 1)
+	assert.Equal(t, ServiceSelenoid, statuses[0].Service)
+	assert.Equal(t, selenoidContainerName, statuses[0].ContainerName)
+	assert.False(t, statuses[0].Running)
+}
+
+func TestInstanceContainerNames(t *testing.T) {
+	c, err := NewDockerConfigurator(&LifecycleConfig{
+		RegistryUrl: mockDockerServer.URL,
+		Quiet:       true,
+		Instance:    "team-a",
+	})
+	assert.NoError(t, err)
+	defer c.Close()
+	assert.Equal(t, "team-a_selenoid", c.Status().ContainerName)
+	assert.Equal(t, "team-a_selenoid-ui", c.UIStatus().ContainerName)
+	assert.Equal(t, "team-a", c.Status().Instance)
 }
 
 func TestDownload(t *testing.T) {
@@ -496,10 +729,10 @@ func TestDownload(t *testing.T) {
 	})
 	assert.NoError(t, err)
 	assert.True(t, c.IsDownloaded())
-	ref, err := c.Download()
+	ref, err := c.Download(context.Background())
 	assert.NoError(t, err)
 	assert.NotNil(t, ref)
-	assert.NoError(t, c.PrintArgs())
+	assert.NoError(t, c.PrintArgs(context.Background()))
 }
 
 func TestDownloadUI(t *testing.T) {
@@ -514,10 +747,10 @@ func TestDownloadUI(t *testing.T) {
 	setImageName(selenoidUIImage)
 	assert.NoError(t, err)
 	assert.True(t, c.IsUIDownloaded())
-	ref, err := c.DownloadUI()
+	ref, err := c.DownloadUI(context.Background())
 	assert.NoError(t, err)
 	assert.NotNil(t, ref)
-	assert.NoError(t, c.PrintUIArgs())
+	assert.NoError(t, c.PrintUIArgs(context.Background()))
 }
 
 func TestGetSelenoidImage(t *testing.T) {
@@ -624,22 +857,3 @@ func TestValidEnviron(t *testing.T) {
 	assert.Equal(t, validateEnviron([]string{"=::=::"}), []string{})
 	assert.Equal(t, validateEnviron([]string{"HOMEDRIVE=C:", "DOCKER_HOST=192.168.0.1", "=::=::"}), []string{"HOMEDRIVE=C:", "DOCKER_HOST=192.168.0.1"})
 }
-
-func TestParseRequestedBrowsers(t *testing.T) {
-	output := parseRequestedBrowsers(&Logger{}, "firefox:>45.0,51.0;opera; android:7.1;firefox:<50.0")
-	assert.Len(t, output, 3)
-
-	ff, ok := output["firefox"]
-	assert.True(t, ok)
-	assert.NotNil(t, ff)
-	assert.Len(t, ff, 2)
-
-	opera, ok := output["opera"]
-	assert.True(t, ok)
-	assert.Empty(t, opera)
-
-	android, ok := output["android"]
-	assert.True(t, ok)
-	assert.NotNil(t, android)
-	assert.Len(t, android, 1)
-}
@@ -12,7 +12,6 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -24,7 +23,6 @@ import (
 
 	"github.com/aerokube/selenoid/config"
 	"github.com/fatih/color"
-	"github.com/google/go-github/github"
 	"github.com/mitchellh/go-ps"
 	"golang.org/x/text/cases"
 	"golang.org/x/text/language"
@@ -53,6 +51,7 @@ type Architectures map[string]Driver
 type Driver struct {
 	URL      string `json:"url"`
 	Filename string `json:"filename"`
+	SHA256   string `json:"sha256,omitempty"`
 }
 
 type downloadedDriver struct {
@@ -72,11 +71,23 @@ type DriversConfigurator struct {
 	RequestedBrowsersAware
 	LogsAware
 	GracefulAware
+	InstanceAware
+	RetryAware
+	HTTPAware
+	MergeAware
 	DriversInfoUrl string
+	AllProcesses   bool
+	Foreground     bool
+	Supervise      bool
+	CacheDir       string
 
+	ReleaseBaseUrl string
+	GithubToken    string
+	OS             string
+	Arch           string
+
+	// Deprecated: use ReleaseBaseUrl, GithubBaseUrl is only used when ReleaseBaseUrl is empty
 	GithubBaseUrl string
-	OS            string
-	Arch          string
 }
 
 func NewDriversConfigurator(config *LifecycleConfig) *DriversConfigurator {
@@ -92,47 +103,65 @@ func NewDriversConfigurator(config *LifecycleConfig) *DriversConfigurator {
 		RequestedBrowsersAware: RequestedBrowsersAware{Browsers: config.Browsers},
 		LogsAware:              LogsAware{DisableLogs: config.DisableLogs},
 		GracefulAware:          GracefulAware{Graceful: config.Graceful, GracefulTimeout: config.GracefulTimeout},
+		InstanceAware:          InstanceAware{Instance: config.Instance},
+		RetryAware:             RetryAware{Retries: config.Retries, RetryBackoff: config.RetryBackoff},
+		HTTPAware:              HTTPAware{CACert: config.CACert, HTTPTimeout: config.HTTPTimeout},
+		MergeAware:             MergeAware{Merge: config.Merge},
 		DriversInfoUrl:         config.DriversInfoUrl,
+		AllProcesses:           config.AllProcesses,
+		Foreground:             config.Foreground,
+		Supervise:              config.Supervise,
+		CacheDir:               config.CacheDir,
+		ReleaseBaseUrl:         config.ReleaseBaseUrl,
 		GithubBaseUrl:          config.GithubBaseUrl,
+		GithubToken:            config.GithubToken,
 		OS:                     config.OS,
 		Arch:                   config.Arch,
 	}
 }
 
-func (d *DriversConfigurator) Status() {
-	binaryPath := d.getSelenoidBinaryPath()
-	if fileExists(binaryPath) {
-		d.Pointf("Selenoid binary is %s", binaryPath)
-	} else {
-		d.Pointf("Selenoid binary is not downloaded")
+func (d *DriversConfigurator) Status() *ServiceStatus {
+	status := &ServiceStatus{
+		Service:   ServiceSelenoid,
+		Instance:  d.Instance,
+		Mode:      ModeDrivers,
+		ConfigDir: d.ConfigDir,
+	}
+	d.fillBinaryStatus(status, d.getSelenoidBinaryPath())
+	fillConfigStatus(status, getSelenoidConfigPath(d.ConfigDir))
+	d.fillProcessStatus(status, d.findSelenoidProcesses())
+	if unit := d.findSelenoidUnit(); unit != nil {
+		status.SystemdUnit = unit.Name
 	}
-	configPath := getSelenoidConfigPath(d.ConfigDir)
-	d.Pointf("Selenoid configuration directory is %s", d.ConfigDir)
-	if fileExists(configPath) {
-		d.Pointf("Selenoid configuration file is %s", configPath)
-	} else {
-		d.Pointf("Selenoid is not configured")
+	return status
+}
+
+func (d *DriversConfigurator) UIStatus() *ServiceStatus {
+	status := &ServiceStatus{
+		Service:  ServiceSelenoidUI,
+		Instance: d.Instance,
+		Mode:     ModeDrivers,
 	}
-	selenoidProcesses := findSelenoidProcesses()
-	if len(selenoidProcesses) > 0 {
-		d.Pointf("Selenoid is running as process %d", selenoidProcesses[0].Pid)
-	} else {
-		d.Pointf("Selenoid is not running")
+	d.fillBinaryStatus(status, d.getSelenoidUIBinaryPath())
+	d.fillProcessStatus(status, d.findSelenoidUIProcesses())
+	if unit := d.findSelenoidUIUnit(); unit != nil {
+		status.SystemdUnit = unit.Name
 	}
+	return status
 }
 
-func (d *DriversConfigurator) UIStatus() {
-	binaryPath := d.getSelenoidUIBinaryPath()
+func (d *DriversConfigurator) fillBinaryStatus(status *ServiceStatus, binaryPath string) {
 	if fileExists(binaryPath) {
-		d.Pointf("Selenoid UI binary is %s", binaryPath)
-	} else {
-		d.Pointf("Selenoid UI binary is not downloaded")
+		status.Downloaded = true
+		status.BinaryPath = binaryPath
 	}
-	selenoidUIProcesses := findSelenoidUIProcesses()
-	if len(selenoidUIProcesses) > 0 {
-		d.Pointf("Selenoid UI is running as process %d", selenoidUIProcesses[0].Pid)
-	} else {
-		d.Pointf("Selenoid UI is not running")
+}
+
+func (d *DriversConfigurator) fillProcessStatus(status *ServiceStatus, processes []*os.Process) {
+	if len(processes) > 0 {
+		status.Running = true
+		status.PID = processes[0].Pid
+		status.Port = d.Port
 	}
 }
 
@@ -160,8 +189,33 @@ func getSelenoidConfigPath(outputDir string) string {
 	return filepath.Join(outputDir, "browsers.json")
 }
 
-func (d *DriversConfigurator) Download() (string, error) {
-	u, err := d.getSelenoidUrl()
+// readSelenoidConfig parses browsers.json
+func readSelenoidConfig(path string) (SelenoidConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read browsers.json from %s: %v", path, err)
+	}
+	var cfg SelenoidConfig
+	err = json.Unmarshal(data, &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse browsers.json from %s: %v", path, err)
+	}
+	return cfg, nil
+}
+
+// writeSelenoidConfig atomically saves browsers.json unless operation was cancelled
+func writeSelenoidConfig(ctx context.Context, outputDir string, data []byte) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return replaceFile(getSelenoidConfigPath(outputDir), 0644, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+func (d *DriversConfigurator) Download(ctx context.Context) (string, error) {
+	u, checksum, err := d.getSelenoidUrl(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to get Selenoid download URL for arch = %s and version = %s: %v", d.Arch, d.Version, err)
 	}
@@ -171,26 +225,26 @@ func (d *DriversConfigurator) Download() (string, error) {
 	}
 	if d.IsRunning() {
 		d.Titlef("Stopping Selenoid to overwrite its binary...")
-		err := d.Stop()
+		err := d.Stop(ctx)
 		if err != nil {
 			return "", fmt.Errorf("failed to stop Selenoid: %v", err)
 		}
 	}
 	d.Titlef("Downloading Selenoid release from %s", color.BlueString(u))
-	outputFile, err := d.downloadFile(u, d.getSelenoidBinaryPath())
+	outputFile, err := d.downloadFile(ctx, u, d.getSelenoidBinaryPath(), checksum)
 	if err != nil {
 		return "", fmt.Errorf("failed to download Selenoid for arch = %s and version = %s: %v", d.Arch, d.Version, err)
 	}
 	d.Titlef("Successfully downloaded Selenoid to %s", color.GreenString(outputFile))
 	return outputFile, nil
 }
-func (d *DriversConfigurator) getSelenoidUrl() (string, error) {
+func (d *DriversConfigurator) getSelenoidUrl(ctx context.Context) (string, string, error) {
 	d.Titlef("Getting Selenoid release information for version: %s", d.Version)
-	return d.getUrl(selenoidRepo, fmt.Errorf("Selenoid binary for %s %s is not available for specified release: %s", strings.Title(d.OS), d.Arch, d.Version))
+	return d.getUrl(ctx, selenoidRepo, fmt.Errorf("Selenoid binary for %s %s is not available for specified release: %s", strings.Title(d.OS), d.Arch, d.Version))
 }
 
-func (d *DriversConfigurator) DownloadUI() (string, error) {
-	u, err := d.getSelenoidUIUrl()
+func (d *DriversConfigurator) DownloadUI(ctx context.Context) (string, error) {
+	u, checksum, err := d.getSelenoidUIUrl(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to get download URL for arch = %s and version = %s: %v", d.Arch, d.Version, err)
 	}
@@ -200,13 +254,13 @@ func (d *DriversConfigurator) DownloadUI() (string, error) {
 	}
 	if d.IsUIRunning() {
 		d.Titlef("Stopping Selenoid UI to overwrite its binary...")
-		err := d.StopUI()
+		err := d.StopUI(ctx)
 		if err != nil {
 			return "", fmt.Errorf("failed to stop Selenoid UI: %v", err)
 		}
 	}
 	d.Titlef("Downloading Selenoid UI release from %s", color.BlueString(u))
-	outputFile, err := d.downloadFile(u, d.getSelenoidUIBinaryPath())
+	outputFile, err := d.downloadFile(ctx, u, d.getSelenoidUIBinaryPath(), checksum)
 	if err != nil {
 		return "", fmt.Errorf("failed to download Selenoid UI for arch = %s and version = %s: %v", d.Arch, d.Version, err)
 	}
@@ -218,66 +272,110 @@ var (
 	title = cases.Title(language.AmericanEnglish)
 )
 
-func (d *DriversConfigurator) getSelenoidUIUrl() (string, error) {
+func (d *DriversConfigurator) getSelenoidUIUrl(ctx context.Context) (string, string, error) {
 	d.Titlef("Getting Selenoid UI release information for version: %s", color.BlueString(d.Version))
-	return d.getUrl(selenoidUIRepo, fmt.Errorf("selenoid ui binary for %s %s is not available for specified release: %s", title.String(d.OS), d.Arch, d.Version))
+	return d.getUrl(ctx, selenoidUIRepo, fmt.Errorf("selenoid ui binary for %s %s is not available for specified release: %s", title.String(d.OS), d.Arch, d.Version))
 }
 
-func (d *DriversConfigurator) getUrl(repo string, missingBinaryError error) (string, error) {
-	ctx := context.Background()
-	client := github.NewClient(nil)
-	if d.GithubBaseUrl != "" {
-		u, err := url.Parse(d.GithubBaseUrl)
-		if err != nil {
-			return "", fmt.Errorf("invalid Github base url [%s]: %v", d.GithubBaseUrl, err)
-		}
-		client.BaseURL = u
-	}
-	var release *github.RepositoryRelease
-	var err error
-	if d.Version != Latest {
-		release, _, err = client.Repositories.GetReleaseByTag(ctx, owner, repo, d.Version)
-	} else {
-		release, _, err = client.Repositories.GetLatestRelease(ctx, owner, repo)
-	}
-
+// getUrl returns release binary download URL and its SHA-256 checksum when release contains a checksum file
+func (d *DriversConfigurator) getUrl(ctx context.Context, repo string, missingBinaryError error) (string, string, error) {
+	assets, err := d.getReleaseAssets(ctx, repo)
 	if err != nil {
-		return "", err
+		return "", "", err
 	}
 
-	if release == nil {
-		return "", fmt.Errorf("unknown release: %s", d.Version)
+	var assetNames []string
+	assetUrls := make(map[string]string)
+	for _, asset := range assets {
+		assetNames = append(assetNames, asset.Name)
+		assetUrls[asset.Name] = asset.URL
 	}
-
-	for _, asset := range release.Assets {
-		assetName := *(asset.Name)
-		if strings.Contains(assetName, d.OS) && strings.Contains(assetName, d.Arch) {
-			return *(asset.BrowserDownloadURL), nil
+	for _, assetName := range assetNames {
+		if strings.Contains(assetName, d.OS) && strings.Contains(assetName, d.Arch) && !isChecksumAsset(assetName) {
+			checksum, err := d.getReleaseChecksum(ctx, assetNames, assetUrls, assetName)
+			if err != nil {
+				return "", "", err
+			}
+			return assetUrls[assetName], checksum, nil
 		}
 	}
-	return "", missingBinaryError
+	return "", "", missingBinaryError
 }
 
-func (d *DriversConfigurator) downloadFile(url string, outputPath string) (string, error) {
-	f, err := os.OpenFile(outputPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.ModePerm)
+func (d *DriversConfigurator) getReleaseChecksum(ctx context.Context, assetNames []string, assetUrls map[string]string, binaryName string) (string, error) {
+	checksumAsset, ok := findChecksumAsset(assetNames, binaryName)
+	if !ok {
+		return "", nil
+	}
+	d.Pointf("Verifying download with %s", color.BlueString(checksumAsset))
+	data, err := d.downloadData(ctx, assetUrls[checksumAsset])
 	if err != nil {
-		return "", err
+		return "", fmt.Errorf("failed to download checksum file %s: %v", checksumAsset, err)
 	}
-	defer f.Close()
+	checksum, err := parseChecksumFile(data, binaryName)
+	if err != nil {
+		return "", fmt.Errorf("invalid checksum file %s: %v", checksumAsset, err)
+	}
+	return checksum, nil
+}
 
-	err = downloadFileWithProgressBar(url, f)
+func (d *DriversConfigurator) downloadFile(ctx context.Context, url string, outputPath string, checksum string) (string, error) {
+	err := d.withCachedFile(ctx, url, func(cache *Cache, path string) error {
+		err := verifyFileChecksum(path, checksum)
+		if err != nil {
+			cache.remove(url)
+			return err
+		}
+		return replaceFile(outputPath, 0755, func(w io.Writer) error {
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = io.Copy(w, f)
+			return err
+		})
+	})
 	if err != nil {
 		return "", err
 	}
 	return outputPath, nil
 }
 
+// withCachedFile downloads file to cache directory and passes its path to fn; without cache directory a temporary one is used
+func (d *DriversConfigurator) withCachedFile(ctx context.Context, url string, fn func(cache *Cache, path string) error) error {
+	cacheDir := d.CacheDir
+	if cacheDir == "" {
+		tmpDir, err := os.MkdirTemp("", "cm-cache")
+		if err != nil {
+			return fmt.Errorf("failed to create temporary directory: %v", err)
+		}
+		defer os.RemoveAll(tmpDir)
+		cacheDir = tmpDir
+	}
+	client, err := d.httpClient()
+	if err != nil {
+		return err
+	}
+	cache := &Cache{Logger: d.Logger, Dir: cacheDir, Client: client}
+	var path string
+	err = d.retry(ctx, &d.Logger, fmt.Sprintf("Downloading %s", url), func() error {
+		var err error
+		path, err = cache.fetch(ctx, url)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return fn(cache, path)
+}
+
 func (d *DriversConfigurator) IsConfigured() bool {
 	return fileExists(getSelenoidConfigPath(d.ConfigDir))
 }
 
-func (d *DriversConfigurator) Configure() (*SelenoidConfig, error) {
-	browsers, err := d.loadAvailableBrowsers()
+func (d *DriversConfigurator) Configure(ctx context.Context) (*SelenoidConfig, error) {
+	browsers, err := d.loadAvailableBrowsers(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load available browsers: %v", err)
 	}
@@ -285,13 +383,23 @@ func (d *DriversConfigurator) Configure() (*SelenoidConfig, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create output directory: %v", err)
 	}
-	downloadedDrivers := d.downloadDrivers(browsers, d.ConfigDir)
-	cfg := d.generateConfig(downloadedDrivers)
+	requestedBrowsers, err := parseRequestedBrowsers(d.Browsers)
+	if err != nil {
+		return nil, err
+	}
+	downloadedDrivers, err := d.downloadDrivers(ctx, browsers, requestedBrowsers, d.ConfigDir)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := d.mergeWithSavedConfig(d.ConfigDir, d.generateConfig(downloadedDrivers))
+	if err != nil {
+		return nil, err
+	}
 	data, err := json.MarshalIndent(cfg, "", "    ")
 	if err != nil {
 		return &cfg, fmt.Errorf("failed to marshal json: %v", err)
 	}
-	return &cfg, os.WriteFile(getSelenoidConfigPath(d.ConfigDir), data, 0644)
+	return &cfg, writeSelenoidConfig(ctx, d.ConfigDir, data)
 }
 
 func (d *DriversConfigurator) generateConfig(downloadedDrivers []downloadedDriver) SelenoidConfig {
@@ -316,10 +424,10 @@ func (d *DriversConfigurator) generateConfig(downloadedDrivers []downloadedDrive
 	return browsers
 }
 
-func (d *DriversConfigurator) loadAvailableBrowsers() (*Browsers, error) {
+func (d *DriversConfigurator) loadAvailableBrowsers(ctx context.Context) (*Browsers, error) {
 	jsonUrl := d.DriversInfoUrl
 	d.Titlef("Downloading browser data from: %s", color.BlueString(jsonUrl))
-	data, err := downloadFile(jsonUrl)
+	data, err := d.downloadData(ctx, jsonUrl)
 	if err != nil {
 		d.Errorf("Browsers data download error: %v", err)
 		return nil, err
@@ -333,10 +441,25 @@ func (d *DriversConfigurator) loadAvailableBrowsers() (*Browsers, error) {
 	return &browsers, nil
 }
 
-func downloadFile(url string) ([]byte, error) {
+// downloadData downloads small files like checksums and drivers information to memory retrying transient failures
+func (d *DriversConfigurator) downloadData(ctx context.Context, url string) ([]byte, error) {
+	client, err := d.httpClient()
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	err = d.retry(ctx, &d.Logger, fmt.Sprintf("Downloading %s", url), func() error {
+		var err error
+		data, err = downloadFile(ctx, client, url)
+		return err
+	})
+	return data, err
+}
+
+func downloadFile(ctx context.Context, client *http.Client, url string) ([]byte, error) {
 	var b bytes.Buffer
 	w := bufio.NewWriter(&b)
-	err := downloadFileWithProgressBar(url, w)
+	err := downloadFileWithProgressBar(ctx, client, url, w)
 	if err != nil {
 		return nil, err
 	}
@@ -344,27 +467,23 @@ func downloadFile(url string) ([]byte, error) {
 	return b.Bytes(), nil
 }
 
-func downloadFileWithProgressBar(url string, w io.Writer) error {
-	resp, err := http.Get(url)
+func downloadFileWithProgressBar(ctx context.Context, client *http.Client, url string, w io.Writer) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("file download error: %v", err)
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("file download error: %v", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return fmt.Errorf("unexpected response code: %d", resp.StatusCode)
+		return unexpectedStatusError(resp.StatusCode)
 	}
 
-	contentLength := int(resp.ContentLength)
-	writer := w
-
-	if contentLength > 0 {
-		bar := pb.New(contentLength).SetUnits(pb.U_BYTES)
-		bar.Output = os.Stderr
-		bar.Start()
-		defer bar.Finish()
-		writer = io.MultiWriter(w, bar)
-	}
+	writer, finish := withProgressBar(w, resp.ContentLength, 0)
+	defer finish()
 
 	_, err = io.Copy(writer, resp.Body)
 	if err != nil {
@@ -373,19 +492,40 @@ func downloadFileWithProgressBar(url string, w io.Writer) error {
 	return nil
 }
 
-func (d *DriversConfigurator) downloadDriver(driver *Driver, dir string) (string, error) {
+// withProgressBar shows download progress when total size is known, current is the number of bytes already downloaded
+func withProgressBar(w io.Writer, total int64, current int64) (io.Writer, func()) {
+	if total <= 0 {
+		return w, func() {}
+	}
+	bar := pb.New64(total).SetUnits(pb.U_BYTES)
+	bar.Output = os.Stderr
+	bar.Set64(current)
+	bar.Start()
+	return io.MultiWriter(w, bar), bar.Finish
+}
+
+func (d *DriversConfigurator) downloadDriver(ctx context.Context, driver *Driver, dir string) (string, error) {
 	if driver.URL == "" {
 		d.Pointf("Assuming that driver is present in %s...", color.BlueString(driver.Filename))
 		return driver.Filename, nil
 	}
 	if d.DownloadNeeded {
 		d.Pointf("Downloading driver from %s...", color.BlueString(driver.URL))
-		data, err := downloadFile(driver.URL)
+		var outputPath string
+		err := d.withCachedFile(ctx, driver.URL, func(cache *Cache, path string) error {
+			err := verifyFileChecksum(path, driver.SHA256)
+			if err != nil {
+				cache.remove(driver.URL)
+				return fmt.Errorf("driver archive %s is corrupted: %v", driver.URL, err)
+			}
+			d.Pointf("Unpacking archive to %s...", color.BlueString(dir))
+			outputPath, err = extractFile(path, driver.Filename, dir)
+			return err
+		})
 		if err != nil {
 			return "", fmt.Errorf("failed to download driver archive: %v", err)
 		}
-		d.Pointf("Unpacking archive to %s...", color.BlueString(dir))
-		return extractFile(data, driver.Filename, dir)
+		return outputPath, nil
 	}
 	return filepath.Join(dir, driver.Filename), nil
 }
@@ -405,14 +545,31 @@ func isTarGzFile(data []byte) bool {
 	return getMagicHeader(data) == gzipMagicHeader
 }
 
-func extractFile(data []byte, filename string, outputDir string) (string, error) {
-	if isZipFile(data) {
-		return unzip(data, filename, outputDir)
-	} else if isTarGzFile(data) {
-		return untar(data, filename, outputDir)
-	} else {
+// extractFile unpacks specified file from zip or tar.gz archive or copies the file as is for other formats
+func extractFile(path string, filename string, outputDir string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file %s: %v", path, err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to open file %s: %v", path, err)
+	}
+	header := make([]byte, 2)
+	n, _ := io.ReadFull(f, header)
+	_, err = f.Seek(0, io.SeekStart)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file %s: %v", path, err)
+	}
+	switch getMagicHeader(header[:n]) {
+	case zipMagicHeader:
+		return unzipReader(f, fi.Size(), filename, outputDir)
+	case gzipMagicHeader:
+		return untarReader(f, filename, outputDir)
+	default:
 		outputPath := filepath.Join(outputDir, filename)
-		err := os.WriteFile(outputPath, data, os.ModePerm)
+		err := outputFile(outputPath, os.ModePerm, f)
 		if err != nil {
 			return "", fmt.Errorf("failed to save file %s: %v", outputPath, err)
 		}
@@ -422,7 +579,11 @@ func extractFile(data []byte, filename string, outputDir string) (string, error)
 
 // Based on http://stackoverflow.com/questions/20357223/easy-way-to-unzip-file-with-golang
 func unzip(data []byte, fileName string, outputDir string) (string, error) {
-	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	return unzipReader(bytes.NewReader(data), int64(len(data)), fileName, outputDir)
+}
+
+func unzipReader(r io.ReaderAt, size int64, fileName string, outputDir string) (string, error) {
+	zr, err := zip.NewReader(r, size)
 
 	// Closure to address file descriptors issue with all the deferred .Close() methods
 	extractAndWriteFile := func(f *zip.File) (string, error) {
@@ -459,8 +620,14 @@ func unzip(data []byte, fileName string, outputDir string) (string, error) {
 
 // Based on https://medium.com/@skdomino/taring-untaring-files-in-go-6b07cf56bc07
 func untar(data []byte, fileName string, outputDir string) (string, error) {
+	return untarReader(bytes.NewReader(data), fileName, outputDir)
+}
 
-	gzr, err := gzip.NewReader(bytes.NewReader(data))
+func untarReader(r io.Reader, fileName string, outputDir string) (string, error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return "", err
+	}
 	defer gzr.Close()
 
 	extractAndWriteFile := func(tr *tar.Reader, header *tar.Header) (string, error) {
@@ -503,44 +670,41 @@ func outputFile(outputPath string, mode os.FileMode, r io.Reader) error {
 	if err != nil {
 		return err
 	}
-	f, err := os.OpenFile(outputPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
-	if err != nil {
+	return replaceFile(outputPath, mode, func(w io.Writer) error {
+		_, err := io.Copy(w, r)
 		return err
-	}
-	defer f.Close()
-
-	_, err = io.Copy(f, r)
-	if err != nil {
-		return err
-	}
-	return nil
+	})
 }
 
-func (d *DriversConfigurator) downloadDrivers(browsers *Browsers, configDir string) []downloadedDriver {
+func (d *DriversConfigurator) downloadDrivers(ctx context.Context, browsers *Browsers, requestedBrowsers map[string]*browserSelector, configDir string) ([]downloadedDriver, error) {
 	var ret []downloadedDriver
 	browsersToIterate := *browsers
-	if d.Browsers != "" {
-		requestedBrowsers := parseRequestedBrowsers(&d.Logger, d.Browsers)
-		if len(requestedBrowsers) > 0 {
-			browsersToIterate = make(Browsers)
-			for browserName := range requestedBrowsers {
-				if browser, ok := (*browsers)[browserName]; ok {
-					browsersToIterate[browserName] = browser
-					continue
-				}
-				d.Errorf("Unsupported browser: %s", browserName)
+	if len(requestedBrowsers) > 0 {
+		browsersToIterate = make(Browsers)
+		var unsupported []string
+		for browserName := range requestedBrowsers {
+			if browser, ok := (*browsers)[browserName]; ok {
+				browsersToIterate[browserName] = browser
+				continue
 			}
+			unsupported = append(unsupported, browserName)
+		}
+		if len(unsupported) > 0 {
+			return nil, unsupportedBrowsersError(unsupported)
 		}
 	}
 
 loop:
 	for browserName, browser := range browsersToIterate {
+		if ctx.Err() != nil {
+			break
+		}
 		goos := runtime.GOOS
 		goarch := runtime.GOARCH
 		if architectures, ok := browser.Files[goos]; ok {
 			if driver, ok := architectures[goarch]; ok {
 				d.Titlef("Processing browser \"%s\"...", color.GreenString(title.String(browserName)))
-				driverPath, err := d.downloadDriver(&driver, configDir)
+				driverPath, err := d.downloadDriver(ctx, &driver, configDir)
 				if err != nil {
 					d.Errorf("Failed to download %s driver: %v", title.String(browserName), err)
 					continue loop
@@ -552,7 +716,7 @@ loop:
 			}
 		}
 	}
-	return ret
+	return ret, nil
 }
 
 func prepareCommand(cmd string, driverPath string) []string {
@@ -568,20 +732,40 @@ func prepareCommand(cmd string, driverPath string) []string {
 }
 
 func (d *DriversConfigurator) IsRunning() bool {
-	selenoidProcesses := findSelenoidProcesses()
+	selenoidProcesses := d.findSelenoidProcesses()
 	return len(selenoidProcesses) > 0
 }
 
 func (d *DriversConfigurator) IsUIRunning() bool {
-	selenoidUIProcesses := findSelenoidUIProcesses()
+	selenoidUIProcesses := d.findSelenoidUIProcesses()
 	return len(selenoidUIProcesses) > 0
 }
 
-func (d *DriversConfigurator) PrintArgs() error {
-	return runCommand(d.getSelenoidBinaryPath(), []string{"--help"}, []string{})
+func (d *DriversConfigurator) PrintArgs(_ context.Context) error {
+	_, err := runCommand(d.getSelenoidBinaryPath(), []string{"--help"}, []string{}, "")
+	return err
+}
+
+func (d *DriversConfigurator) Start(ctx context.Context) error {
+	if unit := d.findSelenoidUnit(); unit != nil {
+		return unit.start()
+	}
+	args, env := d.getSelenoidCommand()
+	if d.Foreground {
+		return d.runForeground(ctx, &foregroundProcess{
+			Name:           "Selenoid",
+			BinaryPath:     d.getSelenoidBinaryPath(),
+			Args:           args,
+			Env:            env,
+			LogFile:        d.getLogFilePath(selenoidLogFileName),
+			PidFile:        d.getPidFilePath(selenoidPidFileName),
+			SupervisorFile: d.getPidFilePath(selenoidSupervisorPidFileName),
+		})
+	}
+	return d.startProcess(d.getSelenoidBinaryPath(), args, env, selenoidLogFileName, selenoidPidFileName)
 }
 
-func (d *DriversConfigurator) Start() error {
+func (d *DriversConfigurator) getSelenoidCommand() ([]string, []string) {
 	args := []string{}
 	overrideArgs := strings.Fields(d.Args)
 	if len(overrideArgs) > 0 {
@@ -597,12 +781,11 @@ func (d *DriversConfigurator) Start() error {
 		args = append(args, "-disable-docker")
 	}
 	if !d.DisableLogs && !contains(args, "-log-output-dir") && isLogSavingSupported(d.Logger, d.Version) {
-		logsConfigDir := getVolumeConfigDir(filepath.Join(d.ConfigDir, logsDirName), append(selenoidConfigDirElem, logsDirName))
+		logsConfigDir := getVolumeConfigDir(filepath.Join(d.ConfigDir, logsDirName), append(d.selenoidConfigDirElem(), logsDirName))
 		args = append(args, "-log-output-dir", logsConfigDir)
 	}
 
-	env := strings.Fields(d.Env)
-	return runCommand(d.getSelenoidBinaryPath(), args, env)
+	return args, strings.Fields(d.Env)
 }
 
 func contains(haystack []string, needle string) bool {
@@ -614,17 +797,60 @@ func contains(haystack []string, needle string) bool {
 	return false
 }
 
-func (d *DriversConfigurator) PrintUIArgs() error {
-	return runCommand(d.getSelenoidUIBinaryPath(), []string{"--help"}, []string{})
+func (d *DriversConfigurator) PrintUIArgs(_ context.Context) error {
+	_, err := runCommand(d.getSelenoidUIBinaryPath(), []string{"--help"}, []string{}, "")
+	return err
+}
+
+func (d *DriversConfigurator) StartUI(ctx context.Context) error {
+	if unit := d.findSelenoidUIUnit(); unit != nil {
+		return unit.start()
+	}
+	args, env := d.getSelenoidUICommand()
+	if d.Foreground {
+		return d.runForeground(ctx, &foregroundProcess{
+			Name:           "Selenoid UI",
+			BinaryPath:     d.getSelenoidUIBinaryPath(),
+			Args:           args,
+			Env:            env,
+			LogFile:        d.getLogFilePath(selenoidUILogFileName),
+			PidFile:        d.getPidFilePath(selenoidUIPidFileName),
+			SupervisorFile: d.getPidFilePath(selenoidUISupervisorPidFileName),
+		})
+	}
+	return d.startProcess(d.getSelenoidUIBinaryPath(), args, env, selenoidUILogFileName, selenoidUIPidFileName)
 }
 
-func (d *DriversConfigurator) StartUI() error {
+func (d *DriversConfigurator) getSelenoidUICommand() ([]string, []string) {
 	args := strings.Fields(d.Args)
 	if !contains(args, "-listen") {
 		args = append(args, "-listen", fmt.Sprintf(":%d", d.Port))
 	}
-	env := strings.Fields(d.Env)
-	return runCommand(d.getSelenoidUIBinaryPath(), args, env)
+	return args, strings.Fields(d.Env)
+}
+
+func (d *DriversConfigurator) startProcess(binaryPath string, args []string, env []string, logFileName string, pidFileName string) error {
+	cmd, err := runCommand(binaryPath, args, env, d.getLogFilePath(logFileName))
+	if err != nil {
+		return err
+	}
+	err = writePidFile(d.getPidFilePath(pidFileName), cmd.Process.Pid, cmd.Path)
+	if err != nil {
+		return fmt.Errorf("failed to save process information: %v", err)
+	}
+	return nil
+}
+
+func (d *DriversConfigurator) getLogFilePath(fileName string) string {
+	return filepath.Join(d.ConfigDir, fileName)
+}
+
+func (d *DriversConfigurator) Logs(_ context.Context, w io.Writer, opts *LogsOptions) error {
+	return showLogFile(d.getLogFilePath(selenoidLogFileName), w, opts)
+}
+
+func (d *DriversConfigurator) UILogs(_ context.Context, w io.Writer, opts *LogsOptions) error {
+	return showLogFile(d.getLogFilePath(selenoidUILogFileName), w, opts)
 }
 
 var killFunc = func(p *os.Process, graceful bool, gracefulTimeout time.Duration) error {
@@ -651,12 +877,32 @@ var killFunc = func(p *os.Process, graceful bool, gracefulTimeout time.Duration)
 	}
 }
 
-func (d *DriversConfigurator) Stop() error {
-	return d.killAllProcesses(findSelenoidProcesses())
+func (d *DriversConfigurator) Stop(_ context.Context) error {
+	if unit := d.findSelenoidUnit(); unit != nil {
+		return unit.stop()
+	}
+	if err := d.stopSupervisor(selenoidSupervisorPidFileName); err != nil {
+		return err
+	}
+	return d.stopProcesses(d.findSelenoidProcesses(), selenoidPidFileName)
 }
 
-func (d *DriversConfigurator) StopUI() error {
-	return d.killAllProcesses(findSelenoidUIProcesses())
+func (d *DriversConfigurator) StopUI(_ context.Context) error {
+	if unit := d.findSelenoidUIUnit(); unit != nil {
+		return unit.stop()
+	}
+	if err := d.stopSupervisor(selenoidUISupervisorPidFileName); err != nil {
+		return err
+	}
+	return d.stopProcesses(d.findSelenoidUIProcesses(), selenoidUIPidFileName)
+}
+
+func (d *DriversConfigurator) stopProcesses(processes []*os.Process, pidFileName string) error {
+	err := d.killAllProcesses(processes)
+	if err != nil {
+		return err
+	}
+	return removePidFile(d.getPidFilePath(pidFileName))
 }
 
 func (d *DriversConfigurator) killAllProcesses(processes []*os.Process) error {
@@ -674,12 +920,49 @@ func (d *DriversConfigurator) Close() error {
 	return nil
 }
 
-func findSelenoidProcesses() []*os.Process {
-	return findProcesses("selenoid")
+func (d *DriversConfigurator) findSelenoidProcesses() []*os.Process {
+	if unit := d.findSelenoidUnit(); unit != nil {
+		return unit.findProcesses()
+	}
+	if d.AllProcesses {
+		return findProcesses("selenoid")
+	}
+	return findTrackedProcesses(d.getPidFilePath(selenoidPidFileName))
+}
+
+func (d *DriversConfigurator) findSelenoidUIProcesses() []*os.Process {
+	if unit := d.findSelenoidUIUnit(); unit != nil {
+		return unit.findProcesses()
+	}
+	if d.AllProcesses {
+		return findProcesses("selenoid-ui")
+	}
+	return findTrackedProcesses(d.getPidFilePath(selenoidUIPidFileName))
+}
+
+func (d *DriversConfigurator) getPidFilePath(fileName string) string {
+	return filepath.Join(d.ConfigDir, fileName)
 }
 
-func findSelenoidUIProcesses() []*os.Process {
-	return findProcesses("selenoid-ui")
+// List returns Selenoid and Selenoid UI processes of default and all named instances
+func (d *DriversConfigurator) List() ([]*ServiceStatus, error) {
+	ret := []*ServiceStatus{}
+	for _, instance := range append([]string{""}, listInstances()...) {
+		selenoidCfg := *d
+		selenoidCfg.Instance = instance
+		selenoidCfg.ConfigDir = GetSelenoidInstanceConfigDir(instance)
+		if status := selenoidCfg.Status(); status.Downloaded || status.Running {
+			ret = append(ret, status)
+		}
+		uiCfg := *d
+		uiCfg.Instance = instance
+		uiCfg.ConfigDir = GetSelenoidUIInstanceConfigDir(instance)
+		if status := uiCfg.UIStatus(); status.Downloaded || status.Running {
+			status.ConfigDir = uiCfg.ConfigDir
+			ret = append(ret, status)
+		}
+	}
+	return ret, nil
 }
 
 func findProcesses(regex string) []*os.Process {
@@ -699,13 +982,23 @@ func findProcesses(regex string) []*os.Process {
 
 var execCommand = exec.Command
 
-func runCommand(command string, args []string, env []string) error {
+func runCommand(command string, args []string, env []string, logFile string) (*exec.Cmd, error) {
 	cmd := execCommand(command, args...)
 	cmd.Stdin = os.Stdin
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
+	if logFile != "" {
+		f, err := os.OpenFile(logFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %v", err)
+		}
+		defer f.Close()
+		cmd.Stdin = nil
+		cmd.Stdout = f
+		cmd.Stderr = f
+	}
 	cmd.Env = env
-	return cmd.Start()
+	return cmd, cmd.Start()
 }
 
 func getSelenoidReleaseFileName() string {
@@ -1,14 +1,17 @@
 package selenoid
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"os"
 	"os/exec"
 	"path"
+	"path/filepath"
 	"reflect"
 	"runtime"
 	"testing"
@@ -22,6 +25,8 @@ import (
 const (
 	previousReleaseTag = "1.2.0"
 	latestReleaseTag   = "1.2.1"
+	checksumReleaseTag = "1.3.0"
+	corruptReleaseTag  = "1.3.1"
 	version            = "version"
 	testEnv            = "MYKEY=myvalue"
 )
@@ -77,6 +82,18 @@ func driversMux() http.Handler {
 						},
 					},
 				},
+				"corrupted": Browser{
+					Command: "%s",
+					Files: Files{
+						goos: {
+							goarch: Driver{
+								URL:      mockServerUrl(mockDriverServer, "/testfile.zip"),
+								Filename: "zip-testfile",
+								SHA256:   sha256Sum([]byte("other")),
+							},
+						},
+					},
+				},
 				"safari": Browser{
 					Command: "%s",
 					Files: Files{
@@ -110,6 +127,20 @@ func driversMux() http.Handler {
 		fmt.Sprintf("/repos/%s/%s/releases/latest", owner, selenoidUIRepo),
 		http.HandlerFunc(getReleaseHandler(latestReleaseTag)),
 	)
+	mux.HandleFunc(
+		fmt.Sprintf("/repos/%s/%s/releases/tags/%s", owner, selenoidRepo, checksumReleaseTag),
+		http.HandlerFunc(getChecksumReleaseHandler(checksumReleaseTag, sha256Sum([]byte(checksumReleaseTag)))),
+	)
+	mux.HandleFunc(
+		fmt.Sprintf("/repos/%s/%s/releases/tags/%s", owner, selenoidRepo, corruptReleaseTag),
+		http.HandlerFunc(getChecksumReleaseHandler(corruptReleaseTag, sha256Sum([]byte(checksumReleaseTag)))),
+	)
+	mux.HandleFunc("/checksums.txt", http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			_, _ = fmt.Fprintf(w, "%s  other_file\n%s  %s\n", sha256Sum([]byte("other")), r.URL.Query().Get("checksum"), releaseFileName)
+		},
+	))
 	mux.HandleFunc("/"+releaseFileName, http.HandlerFunc(
 		func(w http.ResponseWriter, r *http.Request) {
 			version := r.URL.Query().Get(version)
@@ -164,13 +195,45 @@ func TestAllUrlsAreValid(t *testing.T) {
 	}
 }
 
+func TestConfigureDriversCancelled(t *testing.T) {
+	withTmpDir(t, "test-download", func(t *testing.T, dir string) {
+		configurator := NewDriversConfigurator(&LifecycleConfig{
+			ConfigDir:      dir,
+			Browsers:       "first;second",
+			DriversInfoUrl: mockServerUrl(mockDriverServer, "/browsers.json"),
+			Download:       true,
+			Quiet:          true,
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := configurator.Configure(ctx)
+		assert.ErrorContains(t, err, context.Canceled.Error())
+		assert.False(t, configurator.IsConfigured())
+	})
+}
+
+func TestConfigureDriversUnsupportedBrowser(t *testing.T) {
+	withTmpDir(t, "test-download", func(t *testing.T, dir string) {
+		configurator := NewDriversConfigurator(&LifecycleConfig{
+			ConfigDir:      dir,
+			Browsers:       "first;fourth",
+			DriversInfoUrl: mockServerUrl(mockDriverServer, "/browsers.json"),
+			Download:       true,
+			Quiet:          true,
+		})
+		_, err := configurator.Configure(context.Background())
+		assert.EqualError(t, err, "unsupported browsers: fourth")
+		assert.False(t, configurator.IsConfigured())
+	})
+}
+
 func TestConfigureDrivers(t *testing.T) {
 
 	withTmpDir(t, "test-download", func(t *testing.T, dir string) {
 		driversInfoUrl := mockServerUrl(mockDriverServer, "/browsers.json")
 		lcConfig := LifecycleConfig{
 			ConfigDir:      dir,
-			Browsers:       "first;second;safari;fourth",
+			Browsers:       "first;second;safari;corrupted",
 			DriversInfoUrl: driversInfoUrl,
 			Download:       true,
 			Quiet:          false,
@@ -180,7 +243,7 @@ func TestConfigureDrivers(t *testing.T) {
 		}
 		configurator := NewDriversConfigurator(&lcConfig)
 		assert.False(t, configurator.IsConfigured())
-		cfgPointer, err := (*configurator).Configure()
+		cfgPointer, err := (*configurator).Configure(context.Background())
 		assert.NoError(t, err)
 		assert.NotNil(t, cfgPointer)
 
@@ -285,7 +348,7 @@ func readFile(t *testing.T, fileName string) []byte {
 
 func TestDownloadFile(t *testing.T) {
 	fileUrl := mockServerUrl(mockDriverServer, "/testfile")
-	data, err := downloadFile(fileUrl)
+	data, err := downloadFile(context.Background(), http.DefaultClient, fileUrl)
 	if err != nil {
 		t.Fatalf("failed to download file: %v\n", err)
 	}
@@ -328,6 +391,57 @@ func getReleaseHandler(v string) func(http.ResponseWriter, *http.Request) {
 	}
 }
 
+func getChecksumReleaseHandler(v string, checksum string) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		releaseUrl := mockServerUrl(
+			mockDriverServer,
+			fmt.Sprintf("/%s?%s=%s", releaseFileName, version, v),
+		)
+		checksumFileName := "checksums.txt"
+		checksumUrl := mockServerUrl(mockDriverServer, "/checksums.txt?checksum="+checksum)
+		release := github.RepositoryRelease{
+			Assets: []github.ReleaseAsset{
+				{
+					Name:               &checksumFileName,
+					BrowserDownloadURL: &checksumUrl,
+				},
+				{
+					Name:               &releaseFileName,
+					BrowserDownloadURL: &releaseUrl,
+				},
+			},
+		}
+		data, _ := json.Marshal(&release)
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(data)
+	}
+}
+
+func TestDownloadReleaseChecksum(t *testing.T) {
+	withTmpDir(t, "checksum", func(t *testing.T, dir string) {
+		lcConfig := LifecycleConfig{
+			ReleaseBaseUrl: mockDriverServer.URL + "/",
+			ConfigDir:      dir,
+			OS:             runtime.GOOS,
+			Arch:           runtime.GOARCH,
+			Version:        checksumReleaseTag,
+		}
+		configurator := NewDriversConfigurator(&lcConfig)
+		outputPath, err := configurator.Download(context.Background())
+		assert.NoError(t, err)
+		checkContentsEqual(t, outputPath, checksumReleaseTag)
+
+		configurator.Version = corruptReleaseTag
+		_, err = configurator.Download(context.Background())
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "checksum mismatch")
+		checkContentsEqual(t, outputPath, checksumReleaseTag)
+		entries, err := os.ReadDir(dir)
+		assert.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+}
+
 func TestDownloadLatestRelease(t *testing.T) {
 	testDownloadRelease(t, Latest, latestReleaseTag)
 }
@@ -339,21 +453,21 @@ func TestDownloadSpecificRelease(t *testing.T) {
 func testDownloadRelease(t *testing.T, desiredVersion string, expectedFileContents string) {
 	withTmpDir(t, "downloader", func(t *testing.T, dir string) {
 		lcConfig := LifecycleConfig{
-			GithubBaseUrl: mockDriverServer.URL + "/",
-			ConfigDir:     dir,
-			OS:            runtime.GOOS,
-			Arch:          runtime.GOARCH,
-			Version:       desiredVersion,
+			ReleaseBaseUrl: mockDriverServer.URL + "/",
+			ConfigDir:      dir,
+			OS:             runtime.GOOS,
+			Arch:           runtime.GOARCH,
+			Version:        desiredVersion,
 		}
 		configurator := NewDriversConfigurator(&lcConfig)
 		assert.False(t, configurator.IsDownloaded())
 
-		outputPath, err := configurator.Download()
+		outputPath, err := configurator.Download(context.Background())
 		assert.NoError(t, err)
 		assert.NotNil(t, outputPath)
 		checkContentsEqual(t, outputPath, expectedFileContents)
 
-		uiOutputPath, err := configurator.DownloadUI()
+		uiOutputPath, err := configurator.DownloadUI(context.Background())
 		assert.NoError(t, err)
 		assert.NotNil(t, uiOutputPath)
 		checkContentsEqual(t, uiOutputPath, expectedFileContents)
@@ -374,11 +488,11 @@ func checkContentsEqual(t *testing.T, outputPath string, expectedFileContents st
 func TestUnknownRelease(t *testing.T) {
 	downloadShouldFail(t, func(dir string) *DriversConfigurator {
 		lcConfig := LifecycleConfig{
-			GithubBaseUrl: mockDriverServer.URL,
-			ConfigDir:     dir,
-			OS:            runtime.GOOS,
-			Arch:          runtime.GOARCH,
-			Version:       "missing-version",
+			ReleaseBaseUrl: mockDriverServer.URL,
+			ConfigDir:      dir,
+			OS:             runtime.GOOS,
+			Arch:           runtime.GOARCH,
+			Version:        "missing-version",
 		}
 		return NewDriversConfigurator(&lcConfig)
 	})
@@ -387,7 +501,7 @@ func TestUnknownRelease(t *testing.T) {
 func downloadShouldFail(t *testing.T, fn func(string) *DriversConfigurator) {
 	withTmpDir(t, "something", func(t *testing.T, dir string) {
 		configurator := fn(dir)
-		_, err := configurator.Download()
+		_, err := configurator.Download(context.Background())
 		assert.Error(t, err)
 	})
 }
@@ -395,11 +509,11 @@ func downloadShouldFail(t *testing.T, fn func(string) *DriversConfigurator) {
 func TestUnavailableBinary(t *testing.T) {
 	downloadShouldFail(t, func(dir string) *DriversConfigurator {
 		lcConfig := LifecycleConfig{
-			GithubBaseUrl: mockDriverServer.URL,
-			ConfigDir:     dir,
-			OS:            "missing-os",
-			Arch:          "missing-arch",
-			Version:       previousReleaseTag,
+			ReleaseBaseUrl: mockDriverServer.URL,
+			ConfigDir:      dir,
+			OS:             "missing-os",
+			Arch:           "missing-arch",
+			Version:        previousReleaseTag,
 		}
 		return NewDriversConfigurator(&lcConfig)
 	})
@@ -408,11 +522,11 @@ func TestUnavailableBinary(t *testing.T) {
 func TestWrongBaseUrl(t *testing.T) {
 	downloadShouldFail(t, func(dir string) *DriversConfigurator {
 		lcConfig := LifecycleConfig{
-			GithubBaseUrl: ":::bad-url:::",
-			ConfigDir:     dir,
-			OS:            runtime.GOOS,
-			Arch:          runtime.GOARCH,
-			Version:       Latest,
+			ReleaseBaseUrl: ":::bad-url:::",
+			ConfigDir:      dir,
+			OS:             runtime.GOOS,
+			Arch:           runtime.GOARCH,
+			Version:        Latest,
 		}
 		return NewDriversConfigurator(&lcConfig)
 	})
@@ -426,30 +540,63 @@ func TestStartStopProcess(t *testing.T) {
 	}()
 	withTmpDir(t, "something", func(t *testing.T, dir string) {
 		lcConfig := LifecycleConfig{
-			GithubBaseUrl: mockDriverServer.URL,
-			ConfigDir:     dir,
-			OS:            runtime.GOOS,
-			Arch:          runtime.GOARCH,
-			Version:       Latest,
-			Port:          DefaultPort,
+			ReleaseBaseUrl: mockDriverServer.URL,
+			ConfigDir:      dir,
+			OS:             runtime.GOOS,
+			Arch:           runtime.GOARCH,
+			Version:        Latest,
+			Port:           DefaultPort,
 		}
 		configurator := NewDriversConfigurator(&lcConfig)
-		assert.True(t, configurator.IsRunning()) //This is probably true because test binary has name selenoid.test; no fake process is launched
-		assert.NoError(t, configurator.Start())
-		configurator.Status()
-		assert.NoError(t, configurator.Stop())
-		assert.NoError(t, configurator.PrintArgs())
+		assert.False(t, configurator.IsRunning()) //Test binary has name selenoid.test but was not started by cm
+		configurator.AllProcesses = true
+		assert.True(t, configurator.IsRunning())
+		configurator.AllProcesses = false
+		assert.NoError(t, configurator.Start(context.Background()))
+		assert.True(t, fileExists(filepath.Join(dir, selenoidPidFileName)))
+		status := configurator.Status()
+		assert.Equal(t, ModeDrivers, status.Mode)
+		assert.False(t, status.Downloaded)
+		assert.Equal(t, dir, status.ConfigDir)
+		assert.True(t, fileExists(filepath.Join(dir, selenoidLogFileName)))
+		assert.NoError(t, configurator.Logs(context.Background(), io.Discard, &LogsOptions{}))
+		assert.NoError(t, configurator.Stop(context.Background()))
+		assert.NoError(t, configurator.PrintArgs(context.Background()))
 
 		lcConfig.Port = UIDefaultPort
 		assert.False(t, configurator.IsUIRunning())
-		assert.NoError(t, configurator.StartUI())
-		configurator.UIStatus()
-		assert.NoError(t, configurator.StopUI())
-		assert.NoError(t, configurator.PrintUIArgs())
+		assert.NoError(t, configurator.StartUI(context.Background()))
+		assert.Equal(t, ServiceSelenoidUI, configurator.UIStatus().Service)
+		assert.NoError(t, configurator.StopUI(context.Background()))
+		assert.NoError(t, configurator.PrintUIArgs(context.Background()))
 	})
 
 }
 
+func TestStartInstanceProcess(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() {
+		execCommand = exec.Command
+	}()
+	withTmpDir(t, "instance", func(t *testing.T, dir string) {
+		lcConfig := LifecycleConfig{
+			ConfigDir: dir,
+			Version:   Latest,
+			Port:      DefaultPort,
+			Instance:  "team-a",
+		}
+		configurator := NewDriversConfigurator(&lcConfig)
+		assert.False(t, configurator.IsRunning())
+		assert.NoError(t, configurator.Start(context.Background()))
+		tp, err := readPidFile(filepath.Join(dir, selenoidPidFileName))
+		assert.NoError(t, err)
+		assert.NotZero(t, tp.PID)
+		assert.Equal(t, "team-a", configurator.Status().Instance)
+		assert.NoError(t, configurator.Stop(context.Background()))
+		assert.False(t, fileExists(filepath.Join(dir, selenoidPidFileName)))
+	})
+}
+
 func fakeExecCommand(command string, args ...string) *exec.Cmd {
 	cs := []string{"-test.run=TestHelperProcess", "--", command}
 	cs = append(cs, args...)
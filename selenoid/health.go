@@ -0,0 +1,69 @@
+package selenoid
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	selenoidHealthPath   = "/status"
+	selenoidUIHealthPath = "/ping"
+	localhost            = "localhost"
+)
+
+var healthCheckInterval = 500 * time.Millisecond
+
+// getServiceHost returns the host where started containers or processes listen for connections.
+// Containers of a remote runtime (e.g. DOCKER_HOST=tcp://... or CONTAINER_HOST=ssh://...) listen on runtime host.
+func getServiceHost(runtimeHost string) string {
+	u, err := url.Parse(runtimeHost)
+	if err == nil && (u.Scheme == "tcp" || u.Scheme == "ssh") && u.Hostname() != "" {
+		return u.Hostname()
+	}
+	return localhost
+}
+
+func getServiceUrl(host string, port int, path string) string {
+	return fmt.Sprintf("http://%s:%d%s", host, port, path)
+}
+
+func waitForReady(ctx context.Context, u string, timeout time.Duration) error {
+	client := &http.Client{Timeout: 5 * time.Second}
+	deadline := time.Now().Add(timeout)
+	for {
+		err := checkHealth(ctx, client, u)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if time.Now().Add(healthCheckInterval).After(deadline) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(healthCheckInterval):
+		}
+	}
+}
+
+func checkHealth(ctx context.Context, client *http.Client, u string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned unexpected response code: %d", u, resp.StatusCode)
+	}
+	return nil
+}
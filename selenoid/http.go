@@ -0,0 +1,79 @@
+package selenoid
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+)
+
+const DefaultHTTPTimeout = 30 * time.Second
+
+// HTTPAware configures HTTP clients used for registry, GitHub and download requests
+type HTTPAware struct {
+	CACert      string
+	HTTPTimeout time.Duration
+
+	client *http.Client
+}
+
+// httpClient returns client verifying server certificates with system and custom CA certificates, it is created once and reused
+func (h *HTTPAware) httpClient() (*http.Client, error) {
+	if h.client != nil {
+		return h.client, nil
+	}
+	transport, err := h.newHTTPTransport(false)
+	if err != nil {
+		return nil, err
+	}
+	h.client = &http.Client{Transport: transport}
+	return h.client, nil
+}
+
+// newHTTPTransport returns transport honoring HTTP_PROXY, HTTPS_PROXY and NO_PROXY environment variables.
+// Timeout limits connection establishment and waiting for response headers but not transferring response body,
+// so that large files can still be downloaded. Insecure transport does not verify server certificates.
+func (h *HTTPAware) newHTTPTransport(insecure bool) (*http.Transport, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: insecure}
+	if h.CACert != "" && !insecure {
+		pool, err := loadCACerts(h.CACert)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = pool
+	}
+	dialer := &net.Dialer{
+		Timeout:   h.HTTPTimeout,
+		KeepAlive: 30 * time.Second,
+	}
+	return &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		DialContext:           dialer.DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   h.HTTPTimeout,
+		ResponseHeaderTimeout: h.HTTPTimeout,
+		ExpectContinueTimeout: time.Second,
+		IdleConnTimeout:       90 * time.Second,
+		MaxIdleConns:          100,
+		ForceAttemptHTTP2:     true,
+	}, nil
+}
+
+// loadCACerts returns system certificate pool extended with PEM encoded certificates from bundle file
+func loadCACerts(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificates: %v", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM encoded certificates found in %s", path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,62 @@
+package selenoid
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestHTTPClientWithCustomCA(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	withTmpDir(t, "test-ca", func(t *testing.T, dir string) {
+		h := &HTTPAware{}
+		client, err := h.httpClient()
+		assert.NoError(t, err)
+		_, err = client.Get(srv.URL)
+		assert.Error(t, err)
+
+		caCert := filepath.Join(dir, "ca.pem")
+		data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+		assert.NoError(t, os.WriteFile(caCert, data, 0644))
+		h = &HTTPAware{CACert: caCert, HTTPTimeout: DefaultHTTPTimeout}
+		client, err = h.httpClient()
+		assert.NoError(t, err)
+		resp, err := client.Get(srv.URL)
+		assert.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+}
+
+func TestInsecureHTTPTransport(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+	h := &HTTPAware{CACert: "missing.pem"}
+	transport, err := h.newHTTPTransport(true)
+	assert.NoError(t, err)
+	resp, err := (&http.Client{Transport: transport}).Get(srv.URL)
+	assert.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestInvalidCACert(t *testing.T) {
+	withTmpDir(t, "test-ca", func(t *testing.T, dir string) {
+		caCert := filepath.Join(dir, "ca.pem")
+		assert.NoError(t, os.WriteFile(caCert, []byte("not a certificate"), 0644))
+		_, err := (&HTTPAware{CACert: caCert}).httpClient()
+		assert.Error(t, err)
+		_, err = (&HTTPAware{CACert: filepath.Join(dir, "missing.pem")}).httpClient()
+		assert.Error(t, err)
+	})
+}
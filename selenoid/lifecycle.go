@@ -1,10 +1,12 @@
 package selenoid
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"os"
 	"time"
 
 	"github.com/docker/docker/client"
@@ -25,22 +27,46 @@ type LifecycleConfig struct {
 	Version         string
 	Port            int
 	DisableLogs     bool
+	WaitTimeout     time.Duration
+	Instance        string
+	Retries         int
+	RetryBackoff    time.Duration
+	Parallel        int
+	Merge           bool
+	CACert          string
+	HTTPTimeout     time.Duration
 
 	// Docker specific
-	LastVersions int
-	RegistryUrl  string
-	BrowsersJson string
-	ShmSize      int
-	Tmpfs        int
-	VNC          bool
-	UserNS       string
+	Runtime             string
+	LastVersions        int
+	RegistryUrl         string
+	BrowsersRegistryUrl string
+	RegistryUsername    string
+	RegistryPassword    string
+	BrowsersUsername    string
+	BrowsersPassword    string
+	InsecureRegistry    bool
+	ImagesCatalog       string
+	BrowsersJson        string
+	ShmSize             int
+	Tmpfs               int
+	VNC                 bool
+	UserNS              string
 
 	// Drivers specific
 	UseDrivers     bool
 	DriversInfoUrl string
-	GithubBaseUrl  string
+	AllProcesses   bool
+	Foreground     bool
+	Supervise      bool
+	CacheDir       string
+	ReleaseBaseUrl string
+	GithubToken    string
 	OS             string
 	Arch           string
+
+	// Deprecated: use ReleaseBaseUrl, GithubBaseUrl is only used when ReleaseBaseUrl is empty
+	GithubBaseUrl string
 }
 
 type Lifecycle struct {
@@ -49,13 +75,30 @@ type Lifecycle struct {
 	Config       *LifecycleConfig
 	argsAware    ArgsProvider
 	statusAware  StatusProvider
+	lister       InstanceLister
+	logsAware    LogsProvider
+	exporter     ComposeExporter
+	installer    ServiceInstaller
+	bundler      BundleManager
 	downloadable Downloadable
 	configurable Configurable
+	planner      ConfigPlanner
+	validator    ConfigValidator
 	runnable     Runnable
 	closer       io.Closer
+	runtimeHost  string
 }
 
 func NewLifecycle(config *LifecycleConfig) (*Lifecycle, error) {
+	if err := validateInstanceName(config.Instance); err != nil {
+		return nil, err
+	}
+	if config.Foreground && !config.UseDrivers {
+		return nil, errors.New("foreground mode is only supported with drivers")
+	}
+	if config.Supervise && !config.Foreground {
+		return nil, errors.New("supervision requires foreground mode")
+	}
 	lc := Lifecycle{
 		Logger:    Logger{Quiet: config.Quiet},
 		Forceable: Forceable{Force: config.Force},
@@ -66,26 +109,54 @@ func NewLifecycle(config *LifecycleConfig) (*Lifecycle, error) {
 		driversCfg := NewDriversConfigurator(config)
 		lc.argsAware = driversCfg
 		lc.statusAware = driversCfg
+		lc.lister = driversCfg
+		lc.logsAware = driversCfg
+		lc.installer = driversCfg
 		lc.downloadable = driversCfg
 		lc.configurable = driversCfg
+		lc.validator = driversCfg
 		lc.runnable = driversCfg
 		lc.closer = driversCfg
 		return &lc, nil
 	}
-	if !isDockerAvailable() {
-		return nil, errors.New("can not access Docker: make sure you have Docker installed and current user has access permissions")
-	}
-	lc.Titlef("Using %v", color.BlueString("Docker"))
-	dockerCfg, err := NewDockerConfigurator(config)
-	if err != nil {
-		return nil, fmt.Errorf("failed to initialize Docker support: %v", err)
+	var dockerCfg *DockerConfigurator
+	switch config.Runtime {
+	case "", ModeDocker:
+		if !isDockerAvailable() {
+			return nil, errors.New("can not access Docker: make sure you have Docker installed and current user has access permissions")
+		}
+		lc.Titlef("Using %v", color.BlueString("Docker"))
+		cfg, err := NewDockerConfigurator(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Docker support: %v", err)
+		}
+		dockerCfg = cfg
+	case ModePodman:
+		if !isPodmanAvailable() {
+			return nil, errors.New("can not access Podman: make sure Podman API service is running and current user has access permissions")
+		}
+		lc.Titlef("Using %v", color.BlueString("Podman"))
+		cfg, err := NewPodmanConfigurator(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize Podman support: %v", err)
+		}
+		dockerCfg = cfg
+	default:
+		return nil, fmt.Errorf("unsupported container runtime: %s", config.Runtime)
 	}
 	lc.argsAware = dockerCfg
 	lc.statusAware = dockerCfg
+	lc.lister = dockerCfg
+	lc.logsAware = dockerCfg
+	lc.exporter = dockerCfg
+	lc.bundler = dockerCfg
 	lc.downloadable = dockerCfg
 	lc.configurable = dockerCfg
+	lc.planner = dockerCfg
+	lc.validator = dockerCfg
 	lc.runnable = dockerCfg
 	lc.closer = dockerCfg
+	lc.runtimeHost = dockerCfg.docker.DaemonHost()
 	return &lc, nil
 }
 
@@ -95,40 +166,103 @@ func (l *Lifecycle) Close() {
 	}
 }
 
-func (l *Lifecycle) Status() {
-	l.statusAware.Status()
+func (l *Lifecycle) Status(output string) error {
+	return printStatus(&l.Logger, os.Stdout, l.statusAware.Status(), output)
+}
+
+func (l *Lifecycle) UIStatus(output string) error {
+	return printStatus(&l.Logger, os.Stdout, l.statusAware.UIStatus(), output)
+}
+
+func (l *Lifecycle) List(output string) error {
+	statuses, err := l.lister.List()
+	if err != nil {
+		return err
+	}
+	return printStatuses(os.Stdout, statuses, output)
+}
+
+func (l *Lifecycle) Logs(ctx context.Context, opts *LogsOptions) error {
+	return l.logsAware.Logs(ctx, os.Stdout, opts)
 }
 
-func (l *Lifecycle) UIStatus() {
-	l.statusAware.UIStatus()
+func (l *Lifecycle) UILogs(ctx context.Context, opts *LogsOptions) error {
+	return l.logsAware.UILogs(ctx, os.Stdout, opts)
 }
 
-func (l *Lifecycle) Download() error {
+func (l *Lifecycle) ExportCompose(ctx context.Context, outputDir string, uiPort int) error {
+	if l.exporter == nil {
+		return errors.New("exporting compose file is only supported for Docker and Podman")
+	}
+	for _, path := range []string{getComposeFilePath(outputDir), getSelenoidConfigPath(outputDir)} {
+		if fileExists(path) && !l.Force {
+			return fmt.Errorf("file %s already exists: use --force to overwrite", path)
+		}
+	}
+	l.Titlef("Exporting Selenoid and Selenoid UI containers...")
+	err := l.exporter.ExportCompose(ctx, outputDir, uiPort)
+	if err == nil {
+		l.Titlef("Compose file saved to %v", color.GreenString(getComposeFilePath(outputDir)))
+	}
+	return err
+}
+
+func (l *Lifecycle) ExportBundle(ctx context.Context, path string) error {
+	if l.bundler == nil {
+		return errors.New("offline bundles are only supported for Docker and Podman")
+	}
+	if fileExists(path) && !l.Force {
+		return fmt.Errorf("file %s already exists: use --force to overwrite", path)
+	}
+	l.Titlef("Exporting Selenoid bundle...")
+	err := l.bundler.ExportBundle(ctx, path)
+	if err == nil {
+		l.Titlef("Bundle saved to %v", color.GreenString(path))
+	}
+	return err
+}
+
+func (l *Lifecycle) ImportBundle(ctx context.Context, path string) error {
+	if l.bundler == nil {
+		return errors.New("offline bundles are only supported for Docker and Podman")
+	}
+	if l.configurable.IsConfigured() && !l.Force {
+		return fmt.Errorf("selenoid is already configured in %s: use --force to overwrite configuration", l.Config.ConfigDir)
+	}
+	l.Titlef("Importing Selenoid bundle from %v...", color.BlueString(path))
+	err := l.bundler.ImportBundle(ctx, path)
+	if err == nil {
+		l.Titlef("Configuration saved to %v", color.GreenString(getSelenoidConfigPath(l.Config.ConfigDir)))
+	}
+	return err
+}
+
+func (l *Lifecycle) Download(ctx context.Context) error {
 	if l.downloadable.IsDownloaded() && !l.Force {
 		l.Titlef("Selenoid is already downloaded")
 		return nil
 	} else {
 		l.Titlef("Downloading Selenoid...")
-		_, err := l.downloadable.Download()
+		_, err := l.downloadable.Download(ctx)
 		return err
 	}
 }
 
-func (l *Lifecycle) DownloadUI() error {
+func (l *Lifecycle) DownloadUI(ctx context.Context) error {
 	if l.downloadable.IsUIDownloaded() && !l.Force {
 		l.Titlef("Selenoid UI is already downloaded")
 		return nil
 	} else {
 		l.Titlef("Downloading Selenoid UI...")
-		_, err := l.downloadable.DownloadUI()
+		_, err := l.downloadable.DownloadUI(ctx)
 		return err
 	}
 }
 
-func (l *Lifecycle) Configure() error {
+func (l *Lifecycle) Configure(ctx context.Context) error {
 	return chain([]func() error{
 		func() error {
-			return l.Download()
+			return l.Download(ctx)
 		},
 		func() error {
 			if l.configurable.IsConfigured() && !l.Force {
@@ -136,7 +270,7 @@ func (l *Lifecycle) Configure() error {
 				return nil
 			}
 			l.Titlef("Configuring Selenoid...")
-			_, err := l.configurable.Configure()
+			_, err := l.configurable.Configure(ctx)
 			if err == nil {
 				l.Titlef("Configuration saved to %v", color.GreenString(getSelenoidConfigPath(l.Config.ConfigDir)))
 			}
@@ -145,28 +279,62 @@ func (l *Lifecycle) Configure() error {
 	})
 }
 
-func (l *Lifecycle) PrintArgs() error {
+// PlanConfigure prints changes Configure would make to browsers.json and images it would pull
+func (l *Lifecycle) PlanConfigure(ctx context.Context, output string) error {
+	if l.planner == nil {
+		return errors.New("dry run is only supported for Docker and Podman")
+	}
+	l.Titlef("Planning Selenoid configuration...")
+	plan, err := l.planner.PlanConfigure(ctx)
+	if err != nil {
+		return err
+	}
+	return printPlan(&l.Logger, os.Stdout, plan, output)
+}
+
+// Validate checks browsers.json (the one from configuration directory when path is empty) and prints a report
+func (l *Lifecycle) Validate(ctx context.Context, path string, output string) error {
+	if path == "" {
+		path = getSelenoidConfigPath(l.Config.ConfigDir)
+	}
+	l.Titlef("Validating %v...", color.BlueString(path))
+	report, err := l.validator.Validate(ctx, path)
+	if err != nil {
+		return err
+	}
+	err = printValidationReport(&l.Logger, os.Stdout, report, output)
+	if err != nil {
+		return err
+	}
+	if !report.Valid {
+		return fmt.Errorf("%s contains invalid entries", path)
+	}
+	l.Titlef("Configuration is valid")
+	return nil
+}
+
+func (l *Lifecycle) PrintArgs(ctx context.Context) error {
 	return chain([]func() error{
 		func() error {
-			return l.Download()
+			return l.Download(ctx)
 		},
 		func() error {
 			l.Titlef("Printing Selenoid args...")
-			return l.argsAware.PrintArgs()
+			return l.argsAware.PrintArgs(ctx)
 		},
 	})
 }
 
-func (l *Lifecycle) Start() error {
+func (l *Lifecycle) Start(ctx context.Context) error {
 	return chain([]func() error{
 		func() error {
-			return l.Configure()
+			return l.Configure(ctx)
 		},
 		func() error {
 			if l.runnable.IsRunning() {
 				if l.Force {
 					l.Titlef("Stopping previous Selenoid instance...")
-					err := l.Stop()
+					err := l.Stop(ctx)
 					if err != nil {
 						return fmt.Errorf("failed to stop previous Selenoid instance: %v", err)
 					}
@@ -176,8 +344,18 @@ func (l *Lifecycle) Start() error {
 				}
 			}
 
+			if l.Config.Foreground {
+				l.Titlef("Running Selenoid in foreground...")
+				return l.runnable.Start(ctx)
+			}
 			l.Titlef("Starting Selenoid...")
-			err := l.runnable.Start()
+			err := l.runnable.Start(ctx)
+			if err == nil {
+				err = l.waitForReady(ctx, "Selenoid", selenoidHealthPath, l.logsAware.Logs)
+			}
+			if err != nil {
+				l.stopInterrupted(ctx, "Selenoid", l.runnable.Stop)
+			}
 			if err == nil {
 				l.Titlef("Successfully started Selenoid")
 			}
@@ -186,28 +364,72 @@ func (l *Lifecycle) Start() error {
 	})
 }
 
-func (l *Lifecycle) PrintUIArgs() error {
+func (l *Lifecycle) InstallService(ctx context.Context, system bool) error {
+	if l.installer == nil {
+		return errors.New("installing systemd service is only supported in drivers mode")
+	}
+	return chain([]func() error{
+		func() error {
+			return l.Configure(ctx)
+		},
+		func() error {
+			l.Titlef("Installing Selenoid service...")
+			err := l.installer.InstallService(ctx, system)
+			if err == nil {
+				err = l.waitForReady(ctx, "Selenoid", selenoidHealthPath, l.logsAware.Logs)
+			}
+			if err == nil {
+				l.Titlef("Successfully installed Selenoid service")
+			}
+			return err
+		},
+	})
+}
+
+func (l *Lifecycle) InstallUIService(ctx context.Context, system bool) error {
+	if l.installer == nil {
+		return errors.New("installing systemd service is only supported in drivers mode")
+	}
 	return chain([]func() error{
 		func() error {
-			return l.DownloadUI()
+			return l.DownloadUI(ctx)
+		},
+		func() error {
+			l.Titlef("Installing Selenoid UI service...")
+			err := l.installer.InstallUIService(ctx, system)
+			if err == nil {
+				err = l.waitForReady(ctx, "Selenoid UI", selenoidUIHealthPath, l.logsAware.UILogs)
+			}
+			if err == nil {
+				l.Titlef("Successfully installed Selenoid UI service")
+			}
+			return err
+		},
+	})
+}
+
+func (l *Lifecycle) PrintUIArgs(ctx context.Context) error {
+	return chain([]func() error{
+		func() error {
+			return l.DownloadUI(ctx)
 		},
 		func() error {
 			l.Titlef("Printing Selenoid UI args...")
-			return l.argsAware.PrintUIArgs()
+			return l.argsAware.PrintUIArgs(ctx)
 		},
 	})
 }
 
-func (l *Lifecycle) StartUI() error {
+func (l *Lifecycle) StartUI(ctx context.Context) error {
 	return chain([]func() error{
 		func() error {
-			return l.DownloadUI()
+			return l.DownloadUI(ctx)
 		},
 		func() error {
 			if l.runnable.IsUIRunning() {
 				if l.Force {
 					l.Titlef("Stopping previous Selenoid UI instance...")
-					err := l.StopUI()
+					err := l.StopUI(ctx)
 					if err != nil {
 						return fmt.Errorf("failed to stop previous Selenoid UI instance: %v", err)
 					}
@@ -216,8 +438,18 @@ func (l *Lifecycle) StartUI() error {
 					return nil
 				}
 			}
+			if l.Config.Foreground {
+				l.Titlef("Running Selenoid UI in foreground...")
+				return l.runnable.StartUI(ctx)
+			}
 			l.Titlef("Starting Selenoid UI...")
-			err := l.runnable.StartUI()
+			err := l.runnable.StartUI(ctx)
+			if err == nil {
+				err = l.waitForReady(ctx, "Selenoid UI", selenoidUIHealthPath, l.logsAware.UILogs)
+			}
+			if err != nil {
+				l.stopInterrupted(ctx, "Selenoid UI", l.runnable.StopUI)
+			}
 			if err == nil {
 				l.Titlef("Successfully started Selenoid UI")
 			}
@@ -226,26 +458,75 @@ func (l *Lifecycle) StartUI() error {
 	})
 }
 
-func (l *Lifecycle) Stop() error {
+// URL returns WebDriver endpoint of started Selenoid
+func (l *Lifecycle) URL() string {
+	return getServiceUrl(getServiceHost(l.runtimeHost), l.Config.Port, "/wd/hub")
+}
+
+// UIURL returns web interface address of started Selenoid UI
+func (l *Lifecycle) UIURL() string {
+	return getServiceUrl(getServiceHost(l.runtimeHost), l.Config.Port, "/")
+}
+
+func (l *Lifecycle) waitForReady(ctx context.Context, name string, healthPath string, logs func(context.Context, io.Writer, *LogsOptions) error) error {
+	if l.Config.WaitTimeout <= 0 {
+		return nil
+	}
+	u := getServiceUrl(getServiceHost(l.runtimeHost), l.Config.Port, healthPath)
+	l.Titlef("Waiting for %s to become ready at %s...", name, color.BlueString(u))
+	err := waitForReady(ctx, u, l.Config.WaitTimeout)
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return err
+	}
+	var lastLogs bytes.Buffer
+	if logsErr := logs(ctx, &lastLogs, &LogsOptions{Tail: "20"}); logsErr != nil {
+		return fmt.Errorf("%s is not ready after %v: %v", name, l.Config.WaitTimeout, err)
+	}
+	return fmt.Errorf("%s is not ready after %v: %v\nLast logs:\n%s", name, l.Config.WaitTimeout, err, lastLogs.String())
+}
+
+// stopInterrupted stops service whose startup was cancelled, stopping itself is not cancelled
+func (l *Lifecycle) stopInterrupted(ctx context.Context, name string, stop func(context.Context) error) {
+	if ctx.Err() == nil {
+		return
+	}
+	l.Titlef("Startup interrupted, stopping %s...", name)
+	if err := stop(context.WithoutCancel(ctx)); err != nil {
+		l.Errorf("Failed to stop %s: %v", name, err)
+	}
+}
+
+func (l *Lifecycle) IsRunning() bool {
+	return l.runnable.IsRunning()
+}
+
+func (l *Lifecycle) IsUIRunning() bool {
+	return l.runnable.IsUIRunning()
+}
+
+func (l *Lifecycle) Stop(ctx context.Context) error {
 	if !l.runnable.IsRunning() {
 		l.Titlef("Selenoid is not running")
 		return nil
 	}
 	l.Titlef("Stopping Selenoid...")
-	err := l.runnable.Stop()
+	err := l.runnable.Stop(ctx)
 	if err == nil {
 		l.Titlef("Successfully stopped Selenoid")
 	}
 	return err
 }
 
-func (l *Lifecycle) StopUI() error {
+func (l *Lifecycle) StopUI(ctx context.Context) error {
 	if !l.runnable.IsUIRunning() {
 		l.Titlef("Selenoid UI is not running")
 		return nil
 	}
 	l.Titlef("Stopping Selenoid UI...")
-	err := l.runnable.StopUI()
+	err := l.runnable.StopUI(ctx)
 	if err == nil {
 		l.Titlef("Successfully stopped Selenoid UI")
 	}
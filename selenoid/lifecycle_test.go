@@ -1,11 +1,15 @@
 package selenoid
 
 import (
+	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
-	"os"
+	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	assert "github.com/stretchr/testify/require"
 )
@@ -17,12 +21,20 @@ type MockStrategy struct {
 	isUIRunning    bool
 }
 
-func (ms *MockStrategy) Status() {
-	//Does nothing
+func (ms *MockStrategy) Status() *ServiceStatus {
+	return &ServiceStatus{Service: ServiceSelenoid, Mode: ModeDocker}
 }
 
-func (ms *MockStrategy) UIStatus() {
-	//Does nothing
+func (ms *MockStrategy) UIStatus() *ServiceStatus {
+	return &ServiceStatus{Service: ServiceSelenoidUI, Mode: ModeDocker}
+}
+
+func (ms *MockStrategy) Logs(_ context.Context, _ io.Writer, _ *LogsOptions) error {
+	return nil
+}
+
+func (ms *MockStrategy) UILogs(_ context.Context, _ io.Writer, _ *LogsOptions) error {
+	return nil
 }
 
 func (ms *MockStrategy) IsDownloaded() bool {
@@ -33,11 +45,11 @@ func (ms *MockStrategy) IsUIDownloaded() bool {
 	return ms.isDownloaded
 }
 
-func (ms *MockStrategy) Download() (string, error) {
+func (ms *MockStrategy) Download(_ context.Context) (string, error) {
 	return "test", nil
 }
 
-func (ms *MockStrategy) DownloadUI() (string, error) {
+func (ms *MockStrategy) DownloadUI(_ context.Context) (string, error) {
 	return "test", nil
 }
 
@@ -45,7 +57,7 @@ func (ms *MockStrategy) IsConfigured() bool {
 	return false
 }
 
-func (ms *MockStrategy) Configure() (*SelenoidConfig, error) {
+func (ms *MockStrategy) Configure(_ context.Context) (*SelenoidConfig, error) {
 	return &SelenoidConfig{}, nil
 }
 
@@ -57,27 +69,27 @@ func (ms *MockStrategy) IsUIRunning() bool {
 	return ms.isRunning
 }
 
-func (ms *MockStrategy) PrintArgs() error {
+func (ms *MockStrategy) PrintArgs(_ context.Context) error {
 	return nil
 }
 
-func (ms *MockStrategy) PrintUIArgs() error {
+func (ms *MockStrategy) PrintUIArgs(_ context.Context) error {
 	return nil
 }
 
-func (ms *MockStrategy) Start() error {
+func (ms *MockStrategy) Start(_ context.Context) error {
 	return nil
 }
 
-func (ms *MockStrategy) StartUI() error {
+func (ms *MockStrategy) StartUI(_ context.Context) error {
 	return nil
 }
 
-func (ms *MockStrategy) Stop() error {
+func (ms *MockStrategy) Stop(_ context.Context) error {
 	return nil
 }
 
-func (ms *MockStrategy) StopUI() error {
+func (ms *MockStrategy) StopUI(_ context.Context) error {
 	return nil
 }
 
@@ -95,8 +107,7 @@ func TestDockerAvailable(t *testing.T) {
 		w.WriteHeader(http.StatusOK)
 	})
 	mockDockerServer := httptest.NewServer(mux)
-	_ = os.Setenv("DOCKER_HOST", "tcp://"+hostPort(mockDockerServer.URL))
-	defer os.Unsetenv("DOCKER_HOST")
+	t.Setenv("DOCKER_HOST", "tcp://"+hostPort(mockDockerServer.URL))
 
 	assert.True(t, isDockerAvailable())
 }
@@ -113,15 +124,19 @@ func TestLifecycle(t *testing.T) {
 	strategy := MockStrategy{}
 	lc := createTestLifecycle(strategy)
 	defer lc.Close()
-	lc.Status()
-	assert.NoError(t, lc.Download())
-	assert.NoError(t, lc.PrintArgs())
-	assert.NoError(t, lc.Configure())
-	assert.NoError(t, lc.Start())
+	assert.NoError(t, lc.Status(OutputText))
+	assert.NoError(t, lc.Status(OutputJSON))
+	assert.NoError(t, lc.Status(OutputYAML))
+	assert.Error(t, lc.Status("unknown"))
+	assert.NoError(t, lc.Logs(context.Background(), &LogsOptions{}))
+	assert.NoError(t, lc.Download(context.Background()))
+	assert.NoError(t, lc.PrintArgs(context.Background()))
+	assert.NoError(t, lc.Configure(context.Background()))
+	assert.NoError(t, lc.Start(context.Background()))
 	strategy.isRunning = true
-	assert.NoError(t, lc.Start())
+	assert.NoError(t, lc.Start(context.Background()))
 	strategy.isRunning = false
-	assert.NoError(t, lc.Stop())
+	assert.NoError(t, lc.Stop(context.Background()))
 }
 
 func createTestLifecycle(strategy MockStrategy) Lifecycle {
@@ -131,6 +146,7 @@ func createTestLifecycle(strategy MockStrategy) Lifecycle {
 		Config:       &LifecycleConfig{},
 		argsAware:    &strategy,
 		statusAware:  &strategy,
+		logsAware:    &strategy,
 		downloadable: &strategy,
 		configurable: &strategy,
 		runnable:     &strategy,
@@ -142,12 +158,56 @@ func TestUILifecycle(t *testing.T) {
 	strategy := MockStrategy{}
 	lc := createTestLifecycle(strategy)
 	defer lc.Close()
-	lc.UIStatus()
-	assert.NoError(t, lc.DownloadUI())
-	assert.NoError(t, lc.PrintUIArgs())
-	assert.NoError(t, lc.StartUI())
+	assert.NoError(t, lc.UIStatus(OutputText))
+	assert.NoError(t, lc.UILogs(context.Background(), &LogsOptions{}))
+	assert.NoError(t, lc.DownloadUI(context.Background()))
+	assert.NoError(t, lc.PrintUIArgs(context.Background()))
+	assert.NoError(t, lc.StartUI(context.Background()))
 	strategy.isRunning = true
-	assert.NoError(t, lc.StartUI())
+	assert.NoError(t, lc.StartUI(context.Background()))
 	strategy.isRunning = false
-	assert.NoError(t, lc.StopUI())
+	assert.NoError(t, lc.StopUI(context.Background()))
+}
+
+func TestWaitForReady(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+	mux := http.NewServeMux()
+	mux.HandleFunc(selenoidHealthPath, func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+	p, _ := strconv.Atoi(u.Port())
+
+	strategy := MockStrategy{}
+	lc := createTestLifecycle(strategy)
+	lc.Config = &LifecycleConfig{UseDrivers: true, Port: p, WaitTimeout: time.Second}
+	assert.NoError(t, lc.Start(context.Background()))
+
+	healthy.Store(false)
+	err := lc.Start(context.Background())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "503")
+}
+
+func TestServiceURL(t *testing.T) {
+	lc := createTestLifecycle(MockStrategy{})
+	lc.Config = &LifecycleConfig{UseDrivers: true, Port: DefaultPort}
+	assert.Equal(t, "http://localhost:4444/wd/hub", lc.URL())
+	lc.Config.Port = UIDefaultPort
+	assert.Equal(t, "http://localhost:8080/", lc.UIURL())
+}
+
+func TestGetServiceHost(t *testing.T) {
+	assert.Equal(t, localhost, getServiceHost(""))
+	assert.Equal(t, localhost, getServiceHost("unix:///var/run/docker.sock"))
+	assert.Equal(t, localhost, getServiceHost("unix:///run/user/1000/podman/podman.sock"))
+	assert.Equal(t, "192.168.0.1", getServiceHost("tcp://192.168.0.1:2375"))
+	assert.Equal(t, "example.com", getServiceHost("ssh://core@example.com:22/run/podman/podman.sock"))
 }
@@ -0,0 +1,121 @@
+package selenoid
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	selenoidLogFileName   = "selenoid.log"
+	selenoidUILogFileName = "selenoid-ui.log"
+	logTimestampLayout    = "2006/01/02 15:04:05"
+	allLines              = "all"
+)
+
+var logsFollowInterval = 500 * time.Millisecond
+
+type LogsOptions struct {
+	Follow bool
+	Since  string
+	Tail   string
+}
+
+// parseSince accepts either a relative duration (e.g. "10m") or an RFC 3339 timestamp
+func parseSince(since string) (time.Time, error) {
+	if since == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(since); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	t, err := time.Parse(time.RFC3339, since)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid since value %s: should be a duration or an RFC 3339 timestamp", since)
+	}
+	return t, nil
+}
+
+func parseTail(tail string) (int, error) {
+	if tail == "" || tail == allLines {
+		return -1, nil
+	}
+	n, err := strconv.Atoi(tail)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid tail value %s: should be a non-negative number or \"all\"", tail)
+	}
+	return n, nil
+}
+
+func showLogFile(path string, w io.Writer, opts *LogsOptions) error {
+	since, err := parseSince(opts.Since)
+	if err != nil {
+		return err
+	}
+	tail, err := parseTail(opts.Tail)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("log file %s does not exist: start service to create it", path)
+		}
+		return fmt.Errorf("failed to open log file: %v", err)
+	}
+	defer f.Close()
+
+	lines, err := readLogLines(f, since)
+	if err != nil {
+		return fmt.Errorf("failed to read log file: %v", err)
+	}
+	if tail >= 0 && tail < len(lines) {
+		lines = lines[len(lines)-tail:]
+	}
+	for _, line := range lines {
+		_, _ = fmt.Fprintln(w, line)
+	}
+	if opts.Follow {
+		return followLogFile(f, w)
+	}
+	return nil
+}
+
+// Lines without a timestamp (e.g. stack traces) are shown when the previous timestamped line is
+func readLogLines(r io.Reader, since time.Time) ([]string, error) {
+	var lines []string
+	keep := since.IsZero()
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !since.IsZero() && len(line) >= len(logTimestampLayout) {
+			if t, err := time.ParseInLocation(logTimestampLayout, line[:len(logTimestampLayout)], time.Local); err == nil {
+				keep = !t.Before(since)
+			}
+		}
+		if keep {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+func followLogFile(f *os.File, w io.Writer) error {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := f.Read(buf)
+		if n > 0 {
+			_, _ = w.Write(buf[:n])
+		}
+		if err == io.EOF {
+			time.Sleep(logsFollowInterval)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to follow log file: %v", err)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package selenoid
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestParseSince(t *testing.T) {
+	zero, err := parseSince("")
+	assert.NoError(t, err)
+	assert.True(t, zero.IsZero())
+
+	relative, err := parseSince("10m")
+	assert.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(-10*time.Minute), relative, time.Second)
+
+	absolute, err := parseSince("2024-01-02T13:23:37Z")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 1, 2, 13, 23, 37, 0, time.UTC), absolute)
+
+	_, err = parseSince("yesterday")
+	assert.Error(t, err)
+}
+
+func TestParseTail(t *testing.T) {
+	n, err := parseTail("all")
+	assert.NoError(t, err)
+	assert.Equal(t, -1, n)
+	n, err = parseTail("5")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, n)
+	_, err = parseTail("-1")
+	assert.Error(t, err)
+}
+
+func TestShowLogFile(t *testing.T) {
+	withTmpDir(t, "test-logs", func(t *testing.T, dir string) {
+		logFile := filepath.Join(dir, selenoidLogFileName)
+		assert.Error(t, showLogFile(logFile, &bytes.Buffer{}, &LogsOptions{}))
+
+		lines := []string{
+			"2020/01/01 10:00:00 [INIT] [Loading configuration files...]",
+			"2020/01/01 10:00:01 [INIT] [Listening on :4444]",
+			"goroutine 1 [running]:",
+			"2020/01/01 10:00:02 [NEW_REQUEST]",
+		}
+		assert.NoError(t, os.WriteFile(logFile, []byte(strings.Join(lines, "\n")+"\n"), 0644))
+
+		var all bytes.Buffer
+		assert.NoError(t, showLogFile(logFile, &all, &LogsOptions{}))
+		assert.Equal(t, strings.Join(lines, "\n")+"\n", all.String())
+
+		var last bytes.Buffer
+		assert.NoError(t, showLogFile(logFile, &last, &LogsOptions{Tail: "1"}))
+		assert.Equal(t, lines[3]+"\n", last.String())
+
+		since := time.Date(2020, 1, 1, 10, 0, 1, 0, time.Local).Format(time.RFC3339)
+		var recent bytes.Buffer
+		assert.NoError(t, showLogFile(logFile, &recent, &LogsOptions{Since: since}))
+		assert.Equal(t, strings.Join(lines[1:], "\n")+"\n", recent.String())
+	})
+}
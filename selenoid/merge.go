@@ -0,0 +1,120 @@
+package selenoid
+
+import (
+	"strings"
+
+	"github.com/aerokube/selenoid/config"
+)
+
+type MergeAware struct {
+	Merge bool
+}
+
+// mergeWithSavedConfig merges generated configuration into existing browsers.json when merge mode is enabled
+func (m *MergeAware) mergeWithSavedConfig(configDir string, generated SelenoidConfig) (SelenoidConfig, error) {
+	if !m.Merge {
+		return generated, nil
+	}
+	configPath := getSelenoidConfigPath(configDir)
+	if !fileExists(configPath) {
+		return generated, nil
+	}
+	current, err := readSelenoidConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return mergeConfigs(current, generated), nil
+}
+
+// mergeConfigs adds generated browser versions to current configuration and prunes versions that were not generated.
+// Settings generated by cm replace existing ones, fields added manually (e.g. hosts or volumes) are kept.
+// Browsers missing in generated configuration are left as is.
+func mergeConfigs(current SelenoidConfig, generated SelenoidConfig) SelenoidConfig {
+	ret := make(SelenoidConfig)
+	for browserName, versions := range current {
+		ret[browserName] = versions
+	}
+	for browserName, versions := range generated {
+		currentVersions, ok := current[browserName]
+		if !ok {
+			ret[browserName] = versions
+			continue
+		}
+		merged := config.Versions{
+			Default:  versions.Default,
+			Versions: make(map[string]*config.Browser),
+		}
+		for version, browser := range versions.Versions {
+			if currentBrowser, ok := currentVersions.Versions[version]; ok {
+				merged.Versions[version] = mergeBrowsers(currentBrowser, browser)
+				continue
+			}
+			merged.Versions[version] = browser
+		}
+		ret[browserName] = merged
+	}
+	return ret
+}
+
+// mergeBrowsers overlays generated settings on existing ones: environment variables are merged by name,
+// tmpfs by mount point and labels by key, so that entries added manually are kept
+func mergeBrowsers(current *config.Browser, generated *config.Browser) *config.Browser {
+	if current == nil {
+		return generated
+	}
+	ret := *current
+	ret.Image = generated.Image
+	ret.Port = generated.Port
+	ret.Path = generated.Path
+	ret.Env = mergeEnv(current.Env, generated.Env)
+	ret.Tmpfs = mergeMaps(current.Tmpfs, generated.Tmpfs)
+	if generated.ShmSize > 0 {
+		ret.ShmSize = generated.ShmSize
+	}
+	ret.Labels = mergeMaps(current.Labels, generated.Labels)
+	return &ret
+}
+
+// mergeEnv replaces values of existing variables keeping their order and appends new ones
+func mergeEnv(current []string, generated []string) []string {
+	if len(generated) == 0 {
+		return current
+	}
+	values := make(map[string]string)
+	for _, v := range generated {
+		name, _, _ := strings.Cut(v, "=")
+		values[name] = v
+	}
+	var ret []string
+	for _, v := range current {
+		name, _, _ := strings.Cut(v, "=")
+		if newValue, ok := values[name]; ok {
+			ret = append(ret, newValue)
+			delete(values, name)
+			continue
+		}
+		ret = append(ret, v)
+	}
+	for _, v := range generated {
+		name, _, _ := strings.Cut(v, "=")
+		if _, ok := values[name]; ok {
+			ret = append(ret, v)
+			delete(values, name)
+		}
+	}
+	return ret
+}
+
+func mergeMaps(current map[string]string, generated map[string]string) map[string]string {
+	if len(generated) == 0 {
+		return current
+	}
+	ret := make(map[string]string)
+	for k, v := range current {
+		ret[k] = v
+	}
+	for k, v := range generated {
+		ret[k] = v
+	}
+	return ret
+}
@@ -0,0 +1,108 @@
+package selenoid
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/aerokube/selenoid/config"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestMergeConfigs(t *testing.T) {
+	current := SelenoidConfig{
+		"firefox": {Default: "45.0", Versions: map[string]*config.Browser{
+			"45.0": {Image: "selenoid/firefox:45.0", Port: "4444", Path: "/wd/hub", Hosts: []string{"example.com:127.0.0.1"}, Env: []string{"TZ=UTC"}, Labels: map[string]string{"team": "qa"}},
+			"44.0": {Image: "selenoid/firefox:44.0", Port: "4444", Path: "/wd/hub"},
+		}},
+		"custom": {Default: "1.0", Versions: map[string]*config.Browser{
+			"1.0": {Image: "example/custom:1.0", Port: "4444", Path: "/"},
+		}},
+		"opera": {Default: "44.0", Versions: map[string]*config.Browser{
+			"44.0": nil,
+		}},
+	}
+	generated := SelenoidConfig{
+		"firefox": {Default: "46.0", Versions: map[string]*config.Browser{
+			"46.0": {Image: "selenoid/firefox:46.0", Port: "4444", Path: "/wd/hub"},
+			"45.0": {Image: "example.com/selenoid/firefox:45.0", Port: "4444", Path: "/wd/hub", ShmSize: 268435456, Labels: map[string]string{protocolLabel: ProtocolWebDriver}},
+		}},
+		"opera": {Default: "44.0", Versions: map[string]*config.Browser{
+			"44.0": {Image: "selenoid/opera:44.0", Port: "4444", Path: "/"},
+		}},
+	}
+	assert.Equal(t, SelenoidConfig{
+		"firefox": {Default: "46.0", Versions: map[string]*config.Browser{
+			"46.0": {Image: "selenoid/firefox:46.0", Port: "4444", Path: "/wd/hub"},
+			"45.0": {
+				Image:   "example.com/selenoid/firefox:45.0",
+				Port:    "4444",
+				Path:    "/wd/hub",
+				Hosts:   []string{"example.com:127.0.0.1"},
+				Env:     []string{"TZ=UTC"},
+				ShmSize: 268435456,
+				Labels:  map[string]string{"team": "qa", protocolLabel: ProtocolWebDriver},
+			},
+		}},
+		"opera":  generated["opera"],
+		"custom": current["custom"],
+	}, mergeConfigs(current, generated))
+}
+
+func TestMergeEnvAndTmpfs(t *testing.T) {
+	current := &config.Browser{
+		Image: "selenoid/chrome:120.0",
+		Env:   []string{"TZ=Europe/Moscow", "HTTP_PROXY=proxy:3128", "LANG=ru_RU.UTF-8"},
+		Tmpfs: map[string]string{"/tmp": "size=128m", "/home/user/Downloads": "size=256m"},
+	}
+	generated := &config.Browser{
+		Image: "selenoid/chrome:120.0",
+		Env:   []string{"LANG=en_US.UTF-8", "ENABLE_VNC=true", "TZ=UTC"},
+		Tmpfs: map[string]string{"/tmp": "size=512m"},
+	}
+	merged := mergeBrowsers(current, generated)
+	assert.Equal(t, []string{"TZ=UTC", "HTTP_PROXY=proxy:3128", "LANG=en_US.UTF-8", "ENABLE_VNC=true"}, merged.Env)
+	assert.Equal(t, map[string]string{"/tmp": "size=512m", "/home/user/Downloads": "size=256m"}, merged.Tmpfs)
+	assert.Equal(t, []string{"TZ=Europe/Moscow", "HTTP_PROXY=proxy:3128", "LANG=ru_RU.UTF-8"}, current.Env)
+
+	merged = mergeBrowsers(current, &config.Browser{Image: "selenoid/chrome:120.0"})
+	assert.Equal(t, current.Env, merged.Env)
+	assert.Equal(t, current.Tmpfs, merged.Tmpfs)
+}
+
+func TestConfigureMerge(t *testing.T) {
+	withTmpDir(t, "test-merge", func(t *testing.T, dir string) {
+		c, err := NewDockerConfigurator(&LifecycleConfig{
+			ConfigDir:   dir,
+			RegistryUrl: mockDockerServer.URL,
+			Browsers:    "opera",
+			Merge:       true,
+			Quiet:       true,
+		})
+		assert.NoError(t, err)
+		defer c.Close()
+		operaImage := c.browsersRegistry.imageRef("selenoid/opera:44.0")
+		current := SelenoidConfig{
+			"opera": {Default: "43.0", Versions: map[string]*config.Browser{
+				"44.0": {Image: operaImage, Port: "4444", Path: "/", Volumes: []string{"/data:/data:ro"}},
+				"43.0": {Image: "selenoid/opera:43.0", Port: "4444", Path: "/"},
+			}},
+		}
+		data, err := json.Marshal(current)
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(getSelenoidConfigPath(dir), data, 0644))
+
+		cfg, err := c.Configure(context.Background())
+		assert.NoError(t, err)
+		expected := SelenoidConfig{
+			"opera": {Default: "44.0", Versions: map[string]*config.Browser{
+				"44.0": {Image: operaImage, Port: "4444", Path: "/", Volumes: []string{"/data:/data:ro"}},
+			}},
+		}
+		assert.Equal(t, expected, *cfg)
+		saved, err := readSelenoidConfig(getSelenoidConfigPath(dir))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, saved)
+	})
+}
@@ -0,0 +1,36 @@
+package selenoid
+
+import "sync"
+
+const DefaultParallel = 4
+
+// ParallelAware limits the number of concurrent registry requests and image pulls
+type ParallelAware struct {
+	Parallel int
+}
+
+// forEachParallel calls fn for every index from 0 to count-1 using at most workers concurrent goroutines
+func forEachParallel(workers int, count int, fn func(int)) {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > count {
+		workers = count
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < count; i++ {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
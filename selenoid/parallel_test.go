@@ -0,0 +1,40 @@
+package selenoid
+
+import (
+	"sync"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestForEachParallel(t *testing.T) {
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	done := make([]bool, 10)
+	release := make(chan struct{})
+	go func() {
+		for i := 0; i < len(done); i++ {
+			release <- struct{}{}
+		}
+	}()
+	forEachParallel(3, len(done), func(i int) {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		<-release
+		mu.Lock()
+		running--
+		done[i] = true
+		mu.Unlock()
+	})
+	assert.LessOrEqual(t, maxRunning, 3)
+	for _, d := range done {
+		assert.True(t, d)
+	}
+	forEachParallel(0, 0, func(i int) {
+		t.Fatal("should not be called")
+	})
+}
@@ -0,0 +1,350 @@
+package selenoid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/aerokube/selenoid/config"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/go-units"
+	"github.com/fvbommel/sortorder"
+	"github.com/heroku/docker-registry-client/registry"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigPlan describes changes configure command would make without pulling images or saving browsers.json
+type ConfigPlan struct {
+	ConfigPath string        `json:"configPath" yaml:"configPath"`
+	Browsers   []BrowserDiff `json:"browsers" yaml:"browsers"`
+	// Pull lists images missing locally, DownloadSize is their compressed size taken from registry manifests
+	Pull         []string `json:"pull" yaml:"pull"`
+	DownloadSize int64    `json:"downloadSize" yaml:"downloadSize"`
+	SizeUnknown  []string `json:"sizeUnknown,omitempty" yaml:"sizeUnknown,omitempty"`
+}
+
+// BrowserDiff describes changes of one browser in browsers.json
+type BrowserDiff struct {
+	Name       string        `json:"name" yaml:"name"`
+	OldDefault string        `json:"oldDefault,omitempty" yaml:"oldDefault,omitempty"`
+	NewDefault string        `json:"newDefault,omitempty" yaml:"newDefault,omitempty"`
+	Added      []string      `json:"added,omitempty" yaml:"added,omitempty"`
+	Removed    []string      `json:"removed,omitempty" yaml:"removed,omitempty"`
+	Changed    []VersionDiff `json:"changed,omitempty" yaml:"changed,omitempty"`
+}
+
+// VersionDiff lists changed settings of a browser version present before and after configuration
+type VersionDiff struct {
+	Version string   `json:"version" yaml:"version"`
+	Changes []string `json:"changes" yaml:"changes"`
+}
+
+// diffConfigs returns changes of browsers sorted by name, unchanged browsers are omitted
+func diffConfigs(current SelenoidConfig, target SelenoidConfig) []BrowserDiff {
+	browserNames := make(map[string]struct{})
+	for browserName := range current {
+		browserNames[browserName] = struct{}{}
+	}
+	for browserName := range target {
+		browserNames[browserName] = struct{}{}
+	}
+	ret := []BrowserDiff{}
+	for browserName := range browserNames {
+		diff := diffVersions(current[browserName], target[browserName])
+		if diff.OldDefault == diff.NewDefault && len(diff.Added) == 0 && len(diff.Removed) == 0 && len(diff.Changed) == 0 {
+			continue
+		}
+		diff.Name = browserName
+		if diff.OldDefault == diff.NewDefault {
+			diff.OldDefault, diff.NewDefault = "", ""
+		}
+		ret = append(ret, diff)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+func diffVersions(current config.Versions, target config.Versions) BrowserDiff {
+	diff := BrowserDiff{OldDefault: current.Default, NewDefault: target.Default}
+	for version, browser := range target.Versions {
+		currentBrowser, ok := current.Versions[version]
+		if !ok || currentBrowser == nil {
+			diff.Added = append(diff.Added, version)
+			continue
+		}
+		if changes := diffBrowsers(currentBrowser, browser); len(changes) > 0 {
+			diff.Changed = append(diff.Changed, VersionDiff{Version: version, Changes: changes})
+		}
+	}
+	for version := range current.Versions {
+		if _, ok := target.Versions[version]; !ok {
+			diff.Removed = append(diff.Removed, version)
+		}
+	}
+	sort.Sort(sort.Reverse(sortorder.Natural(diff.Added)))
+	sort.Sort(sort.Reverse(sortorder.Natural(diff.Removed)))
+	sort.Slice(diff.Changed, func(i, j int) bool {
+		return sortorder.NaturalLess(diff.Changed[j].Version, diff.Changed[i].Version)
+	})
+	return diff
+}
+
+// diffBrowsers returns human-readable changes of browsers.json version settings
+func diffBrowsers(current *config.Browser, target *config.Browser) []string {
+	var ret []string
+	for _, field := range []struct {
+		name          string
+		current, next interface{}
+	}{
+		{"image", current.Image, target.Image},
+		{"port", current.Port, target.Port},
+		{"path", current.Path, target.Path},
+		{"env", current.Env, target.Env},
+		{"tmpfs", current.Tmpfs, target.Tmpfs},
+		{"shmSize", current.ShmSize, target.ShmSize},
+		{"labels", current.Labels, target.Labels},
+		{"volumes", current.Volumes, target.Volumes},
+		{"hosts", current.Hosts, target.Hosts},
+	} {
+		if !isEmptyValue(field.current) || !isEmptyValue(field.next) {
+			if !reflect.DeepEqual(field.current, field.next) {
+				ret = append(ret, fmt.Sprintf("%s: %v -> %v", field.name, field.current, field.next))
+			}
+		}
+	}
+	return ret
+}
+
+// isEmptyValue tells whether value would be omitted from browsers.json, so that nil and empty maps or slices are equal
+func isEmptyValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Int64:
+		return v.Int() == 0
+	}
+	return false
+}
+
+// PlanConfigure computes browsers.json without pulling images and compares it with the existing one
+func (c *DockerConfigurator) PlanConfigure(ctx context.Context) (*ConfigPlan, error) {
+	configPath := getSelenoidConfigPath(c.ConfigDir)
+	current := SelenoidConfig{}
+	if fileExists(configPath) {
+		cfg, err := readSelenoidConfig(configPath)
+		if err != nil {
+			return nil, err
+		}
+		current = cfg
+	}
+	downloadNeeded := c.DownloadNeeded
+	var target SelenoidConfig
+	if c.BrowsersJson != "" {
+		cfg, err := readSelenoidConfig(c.BrowsersJson)
+		if err != nil {
+			return nil, err
+		}
+		target = cfg
+	} else {
+		c.DownloadNeeded = false
+		cfg, err := c.createConfig(ctx)
+		c.DownloadNeeded = downloadNeeded
+		if err != nil {
+			return nil, err
+		}
+		target = cfg
+		if c.Merge {
+			target = mergeConfigs(current, cfg)
+		}
+	}
+	plan := &ConfigPlan{ConfigPath: configPath, Browsers: diffConfigs(current, target), Pull: []string{}}
+	if downloadNeeded {
+		c.estimateDownload(ctx, plan, target)
+	}
+	return plan, nil
+}
+
+// estimateDownload lists images missing locally and sums their sizes from registry manifests
+func (c *DockerConfigurator) estimateDownload(ctx context.Context, plan *ConfigPlan, target SelenoidConfig) {
+	images, err := c.docker.ImageList(ctx, image.ListOptions{})
+	if err != nil {
+		c.Errorf("Failed to list images: %v", err)
+	}
+	present := localImageRefs(images)
+	selenoidRef := c.resolveImageRef(ctx, selenoidImage, c.Version)
+	if !hasTag(selenoidRef) {
+		selenoidRef = imageWithTag(selenoidRef, Latest)
+	}
+	refs := []string{selenoidRef, c.selenoidRegistry.imageRef(videoRecorderImage)}
+	for _, versions := range target {
+		for _, browser := range versions.Versions {
+			if ref, ok := browser.Image.(string); ok {
+				refs = append(refs, ref)
+			}
+		}
+	}
+	sort.Strings(refs)
+	for _, ref := range refs {
+		if !present[ref] && !contains(plan.Pull, ref) {
+			plan.Pull = append(plan.Pull, ref)
+		}
+	}
+
+	for _, ref := range plan.Pull {
+		if r, _, err := c.getPulledImageRegistry(ref); err == nil {
+			c.getRegistryClient(r)
+		}
+	}
+	sizes := make([]int64, len(plan.Pull))
+	sizeErrors := make([]error, len(plan.Pull))
+	forEachParallel(c.Parallel, len(plan.Pull), func(i int) {
+		sizes[i], sizeErrors[i] = c.fetchImageSize(ctx, plan.Pull[i])
+	})
+	for i, ref := range plan.Pull {
+		if sizeErrors[i] != nil {
+			c.Errorf("Failed to estimate size of %s: %v", ref, sizeErrors[i])
+			plan.SizeUnknown = append(plan.SizeUnknown, ref)
+			continue
+		}
+		plan.DownloadSize += sizes[i]
+	}
+}
+
+// hasTag tells whether image reference contains a tag, registry host can also contain a colon before port
+func hasTag(ref string) bool {
+	return strings.Contains(ref[strings.LastIndex(ref, "/")+1:], colon)
+}
+
+// localImageRefs returns references of local images with and without default registry host as it depends on container runtime
+func localImageRefs(images []image.Summary) map[string]bool {
+	ret := make(map[string]bool)
+	for _, img := range images {
+		for _, tag := range img.RepoTags {
+			ret[tag] = true
+			ret[strings.TrimPrefix(tag, "docker.io/")] = true
+		}
+	}
+	return ret
+}
+
+// fetchImageSize returns compressed size of image layers from manifest in the registry image is pulled from
+func (c *DockerConfigurator) fetchImageSize(ctx context.Context, ref string) (int64, error) {
+	r, repository, err := c.getPulledImageRegistry(ref)
+	if err != nil {
+		return 0, err
+	}
+	reg, err := c.getRegistryClient(r)
+	if err != nil {
+		return 0, err
+	}
+	tag := Latest
+	if i := strings.LastIndex(repository, colon); i != -1 {
+		repository, tag = repository[:i], repository[i+1:]
+	}
+	var size int64
+	err = c.retry(ctx, &c.Logger, "Fetching manifest", func() error {
+		var err error
+		size, err = registryImageSize(ctx, reg, repository, tag)
+		return registryError(err)
+	})
+	return size, err
+}
+
+// registryImageSize makes manifest request cancellable as registry client does not support contexts
+func registryImageSize(ctx context.Context, reg *registry.Registry, repository string, tag string) (int64, error) {
+	type result struct {
+		size int64
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		manifest, err := reg.ManifestV2(repository, tag)
+		if err != nil {
+			ch <- result{0, err}
+			return
+		}
+		size := manifest.Config.Size
+		for _, layer := range manifest.Layers {
+			size += layer.Size
+		}
+		ch <- result{size, nil}
+	}()
+	select {
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	case r := <-ch:
+		return r.size, r.err
+	}
+}
+
+func printPlan(logger *Logger, w io.Writer, plan *ConfigPlan, output string) error {
+	switch output {
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "    ")
+		return enc.Encode(plan)
+	case OutputYAML:
+		enc := yaml.NewEncoder(w)
+		defer enc.Close()
+		return enc.Encode(plan)
+	case OutputText, "":
+		printTextPlan(logger, plan)
+		return nil
+	}
+	return fmt.Errorf("unsupported output format: %s", output)
+}
+
+func printTextPlan(logger *Logger, plan *ConfigPlan) {
+	if len(plan.Browsers) == 0 {
+		logger.Titlef("No changes in %s", plan.ConfigPath)
+	} else {
+		logger.Titlef("Changes in %s:", plan.ConfigPath)
+	}
+	for _, b := range plan.Browsers {
+		var changes []string
+		if len(b.Added) > 0 {
+			changes = append(changes, "add "+strings.Join(b.Added, ", "))
+		}
+		if len(b.Removed) > 0 {
+			changes = append(changes, "remove "+strings.Join(b.Removed, ", "))
+		}
+		if b.OldDefault != b.NewDefault {
+			changes = append(changes, fmt.Sprintf("default %s -> %s", displayVersion(b.OldDefault), displayVersion(b.NewDefault)))
+		}
+		if len(b.Changed) > 0 {
+			changes = append(changes, fmt.Sprintf("change %d versions", len(b.Changed)))
+		}
+		logger.Pointf("Browser %s: %s", b.Name, strings.Join(changes, "; "))
+		for _, v := range b.Changed {
+			logger.Pointf("  %s %s: %s", b.Name, v.Version, strings.Join(v.Changes, ", "))
+		}
+	}
+	if len(plan.Pull) == 0 {
+		logger.Titlef("No images to pull")
+		return
+	}
+	logger.Titlef("%d images to pull, estimated download size is %s", len(plan.Pull), units.HumanSize(float64(plan.DownloadSize)))
+	for _, ref := range plan.Pull {
+		logger.Pointf("%s", ref)
+	}
+	if len(plan.SizeUnknown) > 0 {
+		logger.Pointf("Size of %d images is unknown", len(plan.SizeUnknown))
+	}
+}
+
+func displayVersion(version string) string {
+	if version == "" {
+		return "none"
+	}
+	return version
+}
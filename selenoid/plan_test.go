@@ -0,0 +1,109 @@
+package selenoid
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/aerokube/selenoid/config"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestDiffConfigs(t *testing.T) {
+	current := SelenoidConfig{
+		"firefox": {Default: "45.0", Versions: map[string]*config.Browser{
+			"45.0": {Image: "selenoid/firefox:45.0", Port: "4444", Path: "/wd/hub"},
+			"44.0": {Image: "selenoid/firefox:44.0", Port: "4444", Path: "/wd/hub", Env: []string{"TZ=UTC"}},
+		}},
+		"opera": {Default: "44.0", Versions: map[string]*config.Browser{
+			"44.0": {Image: "selenoid/opera:44.0", Port: "4444", Path: "/"},
+		}},
+		"android": {Default: "10.0", Versions: map[string]*config.Browser{
+			"10.0": {Image: "selenoid/android:10.0", Port: "4444", Path: "/wd/hub"},
+		}},
+	}
+	target := SelenoidConfig{
+		"firefox": {Default: "46.0", Versions: map[string]*config.Browser{
+			"46.0": {Image: "selenoid/firefox:46.0", Port: "4444", Path: "/wd/hub"},
+			"45.0": {Image: "selenoid/firefox:45.0", Port: "4444", Path: "/wd/hub", ShmSize: 268435456, Tmpfs: map[string]string{"/tmp": "size=512m"}},
+		}},
+		"opera": {Default: "44.0", Versions: map[string]*config.Browser{
+			"44.0": {Image: "selenoid/opera:44.0", Port: "4444", Path: "/", Env: []string{}},
+		}},
+		"chrome": {Default: "120.0", Versions: map[string]*config.Browser{
+			"120.0": {Image: "selenoid/chrome:120.0", Port: "4444", Path: "/"},
+		}},
+	}
+	assert.Equal(t, []BrowserDiff{
+		{Name: "android", OldDefault: "10.0", Removed: []string{"10.0"}},
+		{Name: "chrome", NewDefault: "120.0", Added: []string{"120.0"}},
+		{
+			Name:       "firefox",
+			OldDefault: "45.0",
+			NewDefault: "46.0",
+			Added:      []string{"46.0"},
+			Removed:    []string{"44.0"},
+			Changed:    []VersionDiff{{Version: "45.0", Changes: []string{"tmpfs: map[] -> map[/tmp:size=512m]", "shmSize: 0 -> 268435456"}}},
+		},
+	}, diffConfigs(current, target))
+	assert.Empty(t, diffConfigs(current, current))
+}
+
+func TestDiffConfigsNullVersion(t *testing.T) {
+	current := SelenoidConfig{
+		"opera": {Default: "44.0", Versions: map[string]*config.Browser{
+			"44.0": nil,
+		}},
+	}
+	target := SelenoidConfig{
+		"opera": {Default: "44.0", Versions: map[string]*config.Browser{
+			"44.0": {Image: "selenoid/opera:44.0", Port: "4444", Path: "/"},
+		}},
+	}
+	assert.Equal(t, []BrowserDiff{{Name: "opera", Added: []string{"44.0"}}}, diffConfigs(current, target))
+}
+
+func TestPlanConfigure(t *testing.T) {
+	withTmpDir(t, "test-plan", func(t *testing.T, dir string) {
+		current := SelenoidConfig{
+			"opera": {Default: "43.0", Versions: map[string]*config.Browser{
+				"43.0": {Image: "selenoid/opera:43.0", Port: "4444", Path: "/"},
+			}},
+		}
+		data, err := json.Marshal(current)
+		assert.NoError(t, err)
+		assert.NoError(t, os.WriteFile(getSelenoidConfigPath(dir), data, 0644))
+
+		c, err := NewDockerConfigurator(&LifecycleConfig{
+			ConfigDir:   dir,
+			RegistryUrl: mockDockerServer.URL,
+			Browsers:    "opera",
+			Download:    true,
+			Version:     Latest,
+			Quiet:       true,
+		})
+		assert.NoError(t, err)
+		defer c.Close()
+		plan, err := c.PlanConfigure(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []BrowserDiff{{Name: "opera", OldDefault: "43.0", NewDefault: "44.0", Added: []string{"44.0"}, Removed: []string{"43.0"}}}, plan.Browsers)
+		operaImage := c.browsersRegistry.imageRef("selenoid/opera:44.0")
+		videoRecorder := c.selenoidRegistry.imageRef(videoRecorderImage)
+		selenoid := c.selenoidRegistry.imageRef("aerokube/selenoid:1.4.1")
+		assert.ElementsMatch(t, []string{selenoid, operaImage, videoRecorder}, plan.Pull)
+		assert.Equal(t, int64(51000), plan.DownloadSize)
+		assert.ElementsMatch(t, []string{selenoid, videoRecorder}, plan.SizeUnknown)
+
+		saved, err := readSelenoidConfig(getSelenoidConfigPath(dir))
+		assert.NoError(t, err)
+		assert.Equal(t, current, saved)
+	})
+}
+
+func TestHasTag(t *testing.T) {
+	assert.True(t, hasTag("aerokube/selenoid:1.4.1"))
+	assert.True(t, hasTag("localhost:5000/aerokube/selenoid:1.4.1"))
+	assert.False(t, hasTag("localhost:5000/aerokube/selenoid"))
+	assert.False(t, hasTag("aerokube/selenoid"))
+}
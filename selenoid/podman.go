@@ -0,0 +1,67 @@
+package selenoid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/docker/docker/client"
+)
+
+const (
+	containerHost       = "CONTAINER_HOST"
+	xdgRuntimeDir       = "XDG_RUNTIME_DIR"
+	podmanSocketElem    = "podman/podman.sock"
+	rootfulPodmanSocket = "/run/podman/podman.sock"
+)
+
+// NewPodmanConfigurator returns a configurator talking to Docker-compatible API of Podman
+func NewPodmanConfigurator(config *LifecycleConfig) (*DockerConfigurator, error) {
+	host, socket := findPodmanHost()
+	if host == "" {
+		return nil, errors.New("can not find Podman socket: make sure Podman API service is running (e.g. systemctl --user enable --now podman.socket)")
+	}
+	return newDockerConfigurator(config, ModePodman, socket, client.WithHost(host))
+}
+
+// findPodmanHost returns Podman API address and a path to its socket when Podman is running locally
+func findPodmanHost() (string, string) {
+	if h := os.Getenv(containerHost); h != "" {
+		u, err := url.Parse(h)
+		if err == nil && u.Scheme == "unix" {
+			return h, u.Path
+		}
+		return h, ""
+	}
+	var candidates []string
+	if dir := os.Getenv(xdgRuntimeDir); dir != "" {
+		candidates = append(candidates, filepath.Join(dir, podmanSocketElem))
+	}
+	candidates = append(candidates,
+		filepath.Join("/run/user", fmt.Sprint(os.Getuid()), podmanSocketElem),
+		rootfulPodmanSocket,
+	)
+	for _, socket := range candidates {
+		if fileExists(socket) {
+			return "unix://" + socket, socket
+		}
+	}
+	return "", ""
+}
+
+func isPodmanAvailable() bool {
+	host, _ := findPodmanHost()
+	if host == "" {
+		return false
+	}
+	cl, err := client.NewClientWithOpts(client.FromEnv, client.WithHost(host))
+	if err != nil {
+		return false
+	}
+	defer cl.Close()
+	_, err = cl.Ping(context.Background())
+	return err == nil
+}
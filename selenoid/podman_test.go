@@ -0,0 +1,57 @@
+package selenoid
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestFindPodmanHost(t *testing.T) {
+	withTmpDir(t, "podman", func(t *testing.T, dir string) {
+		_ = os.Setenv(xdgRuntimeDir, dir)
+		defer os.Unsetenv(xdgRuntimeDir)
+
+		socket := filepath.Join(dir, podmanSocketElem)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(socket), 0755))
+		assert.NoError(t, os.WriteFile(socket, []byte{}, 0644))
+		host, foundSocket := findPodmanHost()
+		assert.Equal(t, "unix://"+socket, host)
+		assert.Equal(t, socket, foundSocket)
+
+		_ = os.Setenv(containerHost, "unix:///custom/podman.sock")
+		host, foundSocket = findPodmanHost()
+		assert.Equal(t, "unix:///custom/podman.sock", host)
+		assert.Equal(t, "/custom/podman.sock", foundSocket)
+
+		_ = os.Setenv(containerHost, "tcp://example.com:8080")
+		defer os.Unsetenv(containerHost)
+		host, foundSocket = findPodmanHost()
+		assert.Equal(t, "tcp://example.com:8080", host)
+		assert.Empty(t, foundSocket)
+	})
+}
+
+func TestPodmanConfigurator(t *testing.T) {
+	_ = os.Setenv(containerHost, mockDockerServer.URL)
+	defer os.Unsetenv(containerHost)
+	c, err := NewPodmanConfigurator(&LifecycleConfig{
+		RegistryUrl: mockDockerServer.URL,
+		Port:        DefaultPort,
+		Version:     Latest,
+	})
+	assert.NoError(t, err)
+	defer c.Close()
+	assert.Equal(t, ModePodman, c.Status().Mode)
+	assert.Empty(t, c.getSocketVolume())
+	assert.NoError(t, c.Start(context.Background()))
+
+	withTmpDir(t, "podman-socket", func(t *testing.T, dir string) {
+		socket := filepath.Join(dir, "podman.sock")
+		assert.NoError(t, os.WriteFile(socket, []byte{}, 0644))
+		c.hostSocket = socket
+		assert.Equal(t, socket+":"+dockerSocket, c.getSocketVolume())
+	})
+}
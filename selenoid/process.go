@@ -0,0 +1,134 @@
+package selenoid
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/mitchellh/go-ps"
+)
+
+const (
+	selenoidPidFileName   = "selenoid.pid"
+	selenoidUIPidFileName = "selenoid-ui.pid"
+)
+
+// trackedProcess is saved to configuration directory when process is started
+type trackedProcess struct {
+	PID        int    `json:"pid"`
+	Executable string `json:"executable"`
+	StartTime  uint64 `json:"startTime,omitempty"`
+}
+
+func writePidFile(path string, pid int, executable string) error {
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+	if abs, err := filepath.Abs(executable); err == nil {
+		executable = abs
+	}
+	tp := &trackedProcess{PID: pid, Executable: executable}
+	if startTime, ok := getProcessStartTime(pid); ok {
+		tp.StartTime = startTime
+	}
+	data, err := json.Marshal(tp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal process information: %v", err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func readPidFile(path string) (*trackedProcess, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tp trackedProcess
+	err = json.Unmarshal(data, &tp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse process information from %s: %v", path, err)
+	}
+	return &tp, nil
+}
+
+// findTrackedProcesses returns a process saved to PID file if it is still alive and is running the same executable
+func findTrackedProcesses(pidFile string) []*os.Process {
+	tp, err := readPidFile(pidFile)
+	if err != nil {
+		return nil
+	}
+	if !isTrackedProcessAlive(tp) {
+		return nil
+	}
+	p, err := os.FindProcess(tp.PID)
+	if err != nil {
+		return nil
+	}
+	return []*os.Process{p}
+}
+
+// PID can be reused by another process, so executable and start time are compared when operating system exposes them
+func isTrackedProcessAlive(tp *trackedProcess) bool {
+	process, err := ps.FindProcess(tp.PID)
+	if err != nil || process == nil {
+		return false
+	}
+	// Executable name can be truncated by operating system
+	if !strings.HasPrefix(filepath.Base(tp.Executable), process.Executable()) {
+		return false
+	}
+	if exe, ok := getProcessExecutable(tp.PID); ok && exe != tp.Executable {
+		return false
+	}
+	if startTime, ok := getProcessStartTime(tp.PID); ok && tp.StartTime != 0 && startTime != tp.StartTime {
+		return false
+	}
+	return true
+}
+
+func removePidFile(path string) error {
+	err := os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove process information: %v", err)
+	}
+	return nil
+}
+
+func getProcessExecutable(pid int) (string, bool) {
+	if runtime.GOOS != "linux" {
+		return "", false
+	}
+	exe, err := os.Readlink(filepath.Join("/proc", strconv.Itoa(pid), "exe"))
+	if err != nil {
+		return "", false
+	}
+	// Binary replaced by update is still tracked
+	return strings.TrimSuffix(exe, " (deleted)"), true
+}
+
+// getProcessStartTime returns process start time in clock ticks since boot as reported in /proc/<pid>/stat
+func getProcessStartTime(pid int) (uint64, bool) {
+	if runtime.GOOS != "linux" {
+		return 0, false
+	}
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return 0, false
+	}
+	// Command name in parentheses can contain spaces, so fields are counted after the closing one
+	stat := string(data)
+	fields := strings.Fields(stat[strings.LastIndex(stat, ")")+1:])
+	const startTimeIndex = 19
+	if len(fields) <= startTimeIndex {
+		return 0, false
+	}
+	startTime, err := strconv.ParseUint(fields[startTimeIndex], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return startTime, true
+}
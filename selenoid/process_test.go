@@ -0,0 +1,49 @@
+package selenoid
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestFindTrackedProcesses(t *testing.T) {
+	withTmpDir(t, "tracked-process", func(t *testing.T, dir string) {
+		pidFile := filepath.Join(dir, selenoidPidFileName)
+		assert.Empty(t, findTrackedProcesses(pidFile))
+
+		executable, err := os.Executable()
+		assert.NoError(t, err)
+		assert.NoError(t, writePidFile(pidFile, os.Getpid(), executable))
+		processes := findTrackedProcesses(pidFile)
+		assert.Len(t, processes, 1)
+		assert.Equal(t, os.Getpid(), processes[0].Pid)
+
+		assert.NoError(t, writePidFile(pidFile, os.Getpid(), filepath.Join(dir, "selenoid_linux_amd64")))
+		assert.Empty(t, findTrackedProcesses(pidFile))
+
+		assert.NoError(t, removePidFile(pidFile))
+		assert.NoError(t, removePidFile(pidFile))
+		assert.False(t, fileExists(pidFile))
+	})
+}
+
+func TestTrackedProcessStartTime(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process start time is only available on Linux")
+	}
+	startTime, ok := getProcessStartTime(os.Getpid())
+	assert.True(t, ok)
+	assert.NotZero(t, startTime)
+
+	executable, err := os.Executable()
+	assert.NoError(t, err)
+	executable, err = filepath.EvalSymlinks(executable)
+	assert.NoError(t, err)
+	tp := &trackedProcess{PID: os.Getpid(), Executable: executable, StartTime: startTime}
+	assert.True(t, isTrackedProcessAlive(tp))
+	tp.StartTime = startTime + 1
+	assert.False(t, isTrackedProcessAlive(tp))
+}
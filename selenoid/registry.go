@@ -0,0 +1,155 @@
+package selenoid
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	configtypes "github.com/docker/cli/cli/config/types"
+	"github.com/heroku/docker-registry-client/registry"
+)
+
+// dockerHubAuthKey is the key of Docker Hub credentials in Docker configuration file and credential helpers
+const dockerHubAuthKey = "https://index.docker.io/v1/"
+
+// imageRegistry is a Docker registry images are fetched from
+type imageRegistry struct {
+	url string
+	// host qualifies image references, it is empty for Docker Hub to keep references short
+	host string
+	// clientOnce makes registry client initialized and pinged only once even when requested by parallel workers
+	clientOnce sync.Once
+	client     *registry.Registry
+	clientErr  error
+}
+
+func newImageRegistry(registryUrl string) (*imageRegistry, error) {
+	u, err := url.Parse(registryUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry url %s: %v", registryUrl, err)
+	}
+	r := &imageRegistry{url: strings.TrimSuffix(registryUrl, "/")}
+	if registryUrl != DefaultRegistryUrl {
+		r.host = u.Host
+	}
+	return r, nil
+}
+
+// imageRef returns image reference qualified with registry host
+func (r *imageRegistry) imageRef(ref string) string {
+	if r.host != "" {
+		return fmt.Sprintf("%s/%s", r.host, ref)
+	}
+	return ref
+}
+
+// getAuthKey returns key of registry credentials in Docker configuration file
+func getAuthKey(host string) string {
+	if host == "" {
+		return dockerHubAuthKey
+	}
+	return host
+}
+
+// imageRegistryHost returns registry host of image reference or empty string for Docker Hub images
+func imageRegistryHost(ref string) string {
+	host, _ := splitRegistryHost(ref)
+	if isDockerHubHost(host) {
+		return ""
+	}
+	return host
+}
+
+// splitRegistryHost returns registry host specified in image reference (empty when it is missing) and the rest of reference
+func splitRegistryHost(ref string) (string, string) {
+	i := strings.Index(ref, "/")
+	if i == -1 {
+		return "", ref
+	}
+	host := ref[:i]
+	if host == "localhost" || strings.ContainsAny(host, ".:") {
+		return host, ref[i+1:]
+	}
+	return "", ref
+}
+
+func isDockerHubHost(host string) bool {
+	return host == "docker.io" || host == "index.docker.io"
+}
+
+// getImageRegistry returns registry of catalog image and image repository in this registry.
+// Images without registry host are taken from browsers registry, registries of other hosts are created on demand,
+// so this should be called before starting concurrent workers.
+func (c *DockerConfigurator) getImageRegistry(image string) (*imageRegistry, string, error) {
+	host, repository := splitRegistryHost(image)
+	if host == "" {
+		return c.browsersRegistry, image, nil
+	}
+	if isDockerHubHost(host) {
+		host = ""
+	}
+	r, err := c.getRegistryByHost(host)
+	return r, repository, err
+}
+
+// getPulledImageRegistry returns registry Docker pulls image reference from and image repository in this registry,
+// references without registry host point to Docker Hub
+func (c *DockerConfigurator) getPulledImageRegistry(ref string) (*imageRegistry, string, error) {
+	host, repository := splitRegistryHost(ref)
+	if isDockerHubHost(host) {
+		host = ""
+	}
+	if host == "" && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	r, err := c.getRegistryByHost(host)
+	return r, repository, err
+}
+
+// getRegistryByHost returns configured registry with such host (empty for Docker Hub) or creates a new one
+func (c *DockerConfigurator) getRegistryByHost(host string) (*imageRegistry, error) {
+	for _, r := range []*imageRegistry{c.browsersRegistry, c.selenoidRegistry} {
+		if r.host == host {
+			return r, nil
+		}
+	}
+	if r, ok := c.registries[host]; ok {
+		return r, nil
+	}
+	registryUrl := DefaultRegistryUrl
+	if host != "" {
+		registryUrl = "https://" + host
+	}
+	r, err := newImageRegistry(registryUrl)
+	if err != nil {
+		return nil, err
+	}
+	if c.registries == nil {
+		c.registries = make(map[string]*imageRegistry)
+	}
+	c.registries[host] = r
+	return r, nil
+}
+
+// getAuthConfig returns credentials for registry host specified explicitly or taken from Docker configuration file.
+// Credentials stored with credsStore or credHelpers are requested from docker-credential-* helper binaries.
+// Results are memoized, so this should be called before starting concurrent workers.
+func (c *DockerConfigurator) getAuthConfig(host string) *configtypes.AuthConfig {
+	if authConfig, ok := c.authConfigs[host]; ok {
+		return authConfig
+	}
+	var ret *configtypes.AuthConfig
+	if c.dockerConfig != nil {
+		key := getAuthKey(host)
+		authConfig, err := c.dockerConfig.GetAuthConfig(key)
+		if err != nil {
+			c.Errorf(`Failed to get credentials for "%s": %v`, key, err)
+		} else if authConfig.Username != "" || authConfig.IdentityToken != "" || authConfig.RegistryToken != "" {
+			c.Titlef(`Loaded authentication data for "%s"`, key)
+			ret = &authConfig
+		}
+	}
+	c.authConfigs[host] = ret
+	return ret
+}
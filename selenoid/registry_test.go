@@ -0,0 +1,103 @@
+package selenoid
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	authconfig "github.com/docker/cli/cli/config"
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestImageRegistryHost(t *testing.T) {
+	for ref, host := range map[string]string{
+		"selenoid/chrome:120.0":                 "",
+		"ubuntu":                                "",
+		"docker.io/selenoid/chrome:120.0":       "",
+		"localhost/selenoid/chrome":             "localhost",
+		"localhost:5000/selenoid/chrome":        "localhost:5000",
+		"registry.example.com/selenoid/firefox": "registry.example.com",
+	} {
+		assert.Equal(t, host, imageRegistryHost(ref), ref)
+	}
+}
+
+func TestImageRegistry(t *testing.T) {
+	r, err := newImageRegistry(DefaultRegistryUrl)
+	assert.NoError(t, err)
+	assert.Equal(t, "selenoid/chrome", r.imageRef("selenoid/chrome"))
+
+	r, err = newImageRegistry("https://registry.example.com/")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://registry.example.com", r.url)
+	assert.Equal(t, "registry.example.com/selenoid/chrome", r.imageRef("selenoid/chrome"))
+
+	_, err = newImageRegistry(":::bad-url:::")
+	assert.Error(t, err)
+}
+
+func TestRegistryCredentials(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("credential helper stub is a shell script")
+	}
+	withTmpDir(t, "docker-config", func(t *testing.T, dir string) {
+		config := `{
+			"auths": {"https://index.docker.io/v1/": {"auth": "aHViLXVzZXI6aHViLXBhc3N3b3Jk"}},
+			"credHelpers": {"registry.example.com": "cmtest"}
+		}`
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0644))
+		helper := "#!/bin/sh\nread server\necho '{\"ServerURL\":\"'$server'\",\"Username\":\"helper-user\",\"Secret\":\"helper-password\"}'\n"
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "docker-credential-cmtest"), []byte(helper), 0755))
+		t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+		c := &DockerConfigurator{
+			Logger:              Logger{Quiet: true},
+			RegistryUrl:         "https://private.example.com",
+			BrowsersRegistryUrl: "https://registry.example.com",
+			RegistryUsername:    "flag-user",
+			RegistryPassword:    "flag-password",
+		}
+		assert.NoError(t, c.initRegistries())
+		// Docker configuration directory is determined once per process, so the file is loaded explicitly
+		dockerConfig, err := authconfig.Load(dir)
+		assert.NoError(t, err)
+		c.dockerConfig = dockerConfig
+
+		authConfig := c.getAuthConfig(imageRegistryHost("registry.example.com/selenoid/chrome:120.0"))
+		assert.NotNil(t, authConfig)
+		assert.Equal(t, "helper-user", authConfig.Username)
+		assert.Equal(t, "helper-password", authConfig.Password)
+
+		authConfig = c.getAuthConfig(imageRegistryHost(c.selenoidRegistry.imageRef(selenoidImage)))
+		assert.NotNil(t, authConfig)
+		assert.Equal(t, "flag-user", authConfig.Username)
+
+		authConfig = c.getAuthConfig(imageRegistryHost("selenoid/chrome:120.0"))
+		assert.NotNil(t, authConfig)
+		assert.Equal(t, "hub-user", authConfig.Username)
+		assert.Equal(t, "hub-password", authConfig.Password)
+
+		assert.Nil(t, c.getAuthConfig("unknown.example.com"))
+	})
+}
+
+func TestBrowsersRegistryCredentials(t *testing.T) {
+	c := &DockerConfigurator{
+		Logger:              Logger{Quiet: true},
+		RegistryUrl:         "https://private.example.com",
+		BrowsersRegistryUrl: "https://mirror.example.com",
+		RegistryUsername:    "registry-user",
+		RegistryPassword:    "registry-password",
+		BrowsersUsername:    "mirror-user",
+		BrowsersPassword:    "mirror-password",
+	}
+	assert.NoError(t, c.initRegistries())
+	authConfig := c.getAuthConfig(c.selenoidRegistry.host)
+	assert.NotNil(t, authConfig)
+	assert.Equal(t, "registry-user", authConfig.Username)
+	authConfig = c.getAuthConfig(c.browsersRegistry.host)
+	assert.NotNil(t, authConfig)
+	assert.Equal(t, "mirror-user", authConfig.Username)
+	assert.Equal(t, "mirror-password", authConfig.Password)
+}
@@ -0,0 +1,182 @@
+package selenoid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/google/go-github/github"
+)
+
+const releasesIndexSuffix = ".json"
+
+// ReleasesIndex lists releases served from plain HTTP server instead of GitHub, keyed by repository name (e.g. selenoid or selenoid-ui)
+type ReleasesIndex map[string][]Release
+
+// Release contains downloadable binaries and checksum files of one version
+type Release struct {
+	Version string         `json:"version"`
+	Assets  []ReleaseAsset `json:"assets"`
+}
+
+// ReleaseAsset is a downloadable file, relative URLs are resolved against releases index URL
+type ReleaseAsset struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// isReleasesIndexUrl tells whether release base URL points to releases index file instead of GitHub API
+func isReleasesIndexUrl(u string) bool {
+	return strings.HasSuffix(strings.ToLower(u), releasesIndexSuffix)
+}
+
+// getReleaseBaseUrl returns release base URL falling back to deprecated GitHub base URL
+func (d *DriversConfigurator) getReleaseBaseUrl() string {
+	if d.ReleaseBaseUrl != "" {
+		return d.ReleaseBaseUrl
+	}
+	return d.GithubBaseUrl
+}
+
+// getReleaseAssets returns assets of requested release from releases index or GitHub
+func (d *DriversConfigurator) getReleaseAssets(ctx context.Context, repo string) ([]ReleaseAsset, error) {
+	if isReleasesIndexUrl(d.getReleaseBaseUrl()) {
+		return d.getIndexReleaseAssets(ctx, repo)
+	}
+	return d.getGithubReleaseAssets(ctx, repo)
+}
+
+func (d *DriversConfigurator) getIndexReleaseAssets(ctx context.Context, repo string) ([]ReleaseAsset, error) {
+	baseUrl := d.getReleaseBaseUrl()
+	indexUrl, err := url.Parse(baseUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid release base url [%s]: %v", baseUrl, err)
+	}
+	data, err := d.downloadData(ctx, baseUrl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download releases index: %v", err)
+	}
+	var index ReleasesIndex
+	err = json.Unmarshal(data, &index)
+	if err != nil {
+		return nil, fmt.Errorf("invalid releases index: %v", err)
+	}
+	release := index.find(repo, d.Version)
+	if release == nil {
+		return nil, fmt.Errorf("unknown release: %s", d.Version)
+	}
+	var assets []ReleaseAsset
+	for _, asset := range release.Assets {
+		u, err := indexUrl.Parse(asset.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid url of release asset %s: %v", asset.Name, err)
+		}
+		assets = append(assets, ReleaseAsset{Name: asset.Name, URL: u.String()})
+	}
+	return assets, nil
+}
+
+// find returns release with specified version or the one with the greatest version for latest
+func (index ReleasesIndex) find(repo string, version string) *Release {
+	releases := index[repo]
+	if version != Latest {
+		for i := range releases {
+			if releases[i].Version == version {
+				return &releases[i]
+			}
+		}
+		return nil
+	}
+	var latest *Release
+	var latestVersion *semver.Version
+	for i := range releases {
+		v, err := semver.NewVersion(releases[i].Version)
+		if err != nil {
+			continue
+		}
+		if latestVersion == nil || v.GreaterThan(latestVersion) {
+			latest, latestVersion = &releases[i], v
+		}
+	}
+	if latest == nil && len(releases) > 0 {
+		latest = &releases[0]
+	}
+	return latest
+}
+
+func (d *DriversConfigurator) getGithubReleaseAssets(ctx context.Context, repo string) ([]ReleaseAsset, error) {
+	httpClient, err := d.githubClient()
+	if err != nil {
+		return nil, err
+	}
+	client := github.NewClient(httpClient)
+	if baseUrl := d.getReleaseBaseUrl(); baseUrl != "" {
+		u, err := url.Parse(baseUrl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid release base url [%s]: %v", baseUrl, err)
+		}
+		if !strings.HasSuffix(u.Path, "/") {
+			u.Path += "/"
+		}
+		client.BaseURL = u
+	}
+	var release *github.RepositoryRelease
+	err = d.retry(ctx, &d.Logger, "Getting release information", func() error {
+		var resp *github.Response
+		var err error
+		if d.Version != Latest {
+			release, resp, err = client.Repositories.GetReleaseByTag(ctx, owner, repo, d.Version)
+		} else {
+			release, resp, err = client.Repositories.GetLatestRelease(ctx, owner, repo)
+		}
+		var rateLimitErr *github.RateLimitError
+		if errors.As(err, &rateLimitErr) && d.GithubToken == "" {
+			return permanent(fmt.Errorf("%v (set GITHUB_TOKEN environment variable or --github-token flag to increase the limit)", err))
+		}
+		if err != nil && resp != nil && !isRetryableStatus(resp.StatusCode) {
+			return permanent(err)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if release == nil {
+		return nil, fmt.Errorf("unknown release: %s", d.Version)
+	}
+	var assets []ReleaseAsset
+	for _, asset := range release.Assets {
+		assets = append(assets, ReleaseAsset{Name: asset.GetName(), URL: asset.GetBrowserDownloadURL()})
+	}
+	return assets, nil
+}
+
+// githubClient returns HTTP client for GitHub API requests authenticated with token when it is set
+func (d *DriversConfigurator) githubClient() (*http.Client, error) {
+	client, err := d.httpClient()
+	if err != nil || d.GithubToken == "" {
+		return client, err
+	}
+	transport := client.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &http.Client{Transport: &githubTokenTransport{token: d.GithubToken, base: transport}}, nil
+}
+
+// githubTokenTransport adds personal access token to GitHub API requests, release assets are downloaded without it
+type githubTokenTransport struct {
+	token string
+	base  http.RoundTripper
+}
+
+func (t *githubTokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.Header.Set("Authorization", "token "+t.token)
+	return t.base.RoundTrip(r)
+}
@@ -0,0 +1,99 @@
+package selenoid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-github/github"
+	assert "github.com/stretchr/testify/require"
+)
+
+func newReleasesIndexServer() *httptest.Server {
+	binaryName := fmt.Sprintf("selenoid_%s_%s", runtime.GOOS, runtime.GOARCH)
+	index := ReleasesIndex{
+		selenoidRepo: {
+			{Version: "1.10.0", Assets: []ReleaseAsset{{Name: binaryName, URL: "1.10.0/" + binaryName}}},
+			{Version: "1.11.0", Assets: []ReleaseAsset{{Name: binaryName, URL: "1.11.0/" + binaryName}}},
+		},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mirror/releases.json", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(index)
+	})
+	for _, v := range []string{"1.10.0", "1.11.0"} {
+		v := v
+		mux.HandleFunc("/mirror/"+v+"/"+binaryName, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(v))
+		})
+	}
+	return httptest.NewServer(mux)
+}
+
+func TestDownloadFromReleasesIndex(t *testing.T) {
+	srv := newReleasesIndexServer()
+	defer srv.Close()
+	for version, expected := range map[string]string{Latest: "1.11.0", "1.10.0": "1.10.0"} {
+		withTmpDir(t, "releases-index", func(t *testing.T, dir string) {
+			configurator := NewDriversConfigurator(&LifecycleConfig{
+				ReleaseBaseUrl: srv.URL + "/mirror/releases.json",
+				ConfigDir:      dir,
+				OS:             runtime.GOOS,
+				Arch:           runtime.GOARCH,
+				Version:        version,
+				Quiet:          true,
+			})
+			outputPath, err := configurator.Download(context.Background())
+			assert.NoError(t, err)
+			checkContentsEqual(t, outputPath, expected)
+
+			configurator.Version = "missing-version"
+			_, err = configurator.Download(context.Background())
+			assert.Error(t, err)
+			_, err = configurator.DownloadUI(context.Background())
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestGithubToken(t *testing.T) {
+	var authorization string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		_ = json.NewEncoder(w).Encode(github.RepositoryRelease{})
+	}))
+	defer srv.Close()
+	configurator := NewDriversConfigurator(&LifecycleConfig{
+		ReleaseBaseUrl: srv.URL,
+		GithubToken:    "secret",
+		Version:        Latest,
+		Quiet:          true,
+	})
+	_, err := configurator.getReleaseAssets(context.Background(), selenoidRepo)
+	assert.NoError(t, err)
+	assert.Equal(t, "token secret", authorization)
+}
+
+func TestDeprecatedGithubBaseUrl(t *testing.T) {
+	var requested bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+		_ = json.NewEncoder(w).Encode(github.RepositoryRelease{})
+	}))
+	defer srv.Close()
+	configurator := NewDriversConfigurator(&LifecycleConfig{
+		GithubBaseUrl: srv.URL,
+		Version:       Latest,
+		Quiet:         true,
+	})
+	_, err := configurator.getReleaseAssets(context.Background(), selenoidRepo)
+	assert.NoError(t, err)
+	assert.True(t, requested)
+
+	configurator.ReleaseBaseUrl = "https://example.com/releases.json"
+	assert.Equal(t, "https://example.com/releases.json", configurator.getReleaseBaseUrl())
+}
@@ -0,0 +1,93 @@
+package selenoid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultRetries      = 3
+	DefaultRetryBackoff = time.Second
+
+	maxRetryBackoff = time.Minute
+)
+
+// RetryAware configures retrying of registry, GitHub and download requests failed due to transient errors
+type RetryAware struct {
+	Retries      int
+	RetryBackoff time.Duration
+}
+
+// permanentError marks errors that can not be fixed by retrying (e.g. missing release or image)
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+func permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// isRetryableStatus tells whether request with such HTTP response status code can succeed when repeated
+func isRetryableStatus(code int) bool {
+	return code >= http.StatusInternalServerError || code == http.StatusRequestTimeout || code == http.StatusTooManyRequests
+}
+
+func unexpectedStatusError(code int) error {
+	err := fmt.Errorf("unexpected response code: %d", code)
+	if !isRetryableStatus(code) {
+		return permanent(err)
+	}
+	return err
+}
+
+// retry calls fn until it succeeds, returns a permanent error, context is cancelled or the number of retries is exhausted doubling delay after every attempt
+func (r *RetryAware) retry(ctx context.Context, logger *Logger, action string, fn func() error) error {
+	return r.retryNotify(ctx, func(msg string) {
+		logger.Pointf("%s", msg)
+	}, action, fn)
+}
+
+// retryNotify is the same as retry but passes messages about retries to notify
+func (r *RetryAware) retryNotify(ctx context.Context, notify func(string), action string, fn func() error) error {
+	backoff := r.RetryBackoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		var pe *permanentError
+		if errors.As(err, &pe) {
+			return pe.err
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+		if attempt > r.Retries {
+			if r.Retries > 0 {
+				return fmt.Errorf("%v (gave up after %d attempts)", err, attempt)
+			}
+			return err
+		}
+		notify(fmt.Sprintf("%s failed: %v, retrying in %v (%d of %d retries)", action, err, backoff, attempt, r.Retries))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxRetryBackoff {
+			backoff = maxRetryBackoff
+		}
+	}
+}
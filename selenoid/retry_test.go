@@ -0,0 +1,62 @@
+package selenoid
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestRetry(t *testing.T) {
+	r := &RetryAware{Retries: 2, RetryBackoff: time.Millisecond}
+	logger := &Logger{Quiet: true}
+
+	attempts := 0
+	err := r.retry(context.Background(), logger, "Test", func() error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("transient")
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, attempts)
+
+	attempts = 0
+	err = r.retry(context.Background(), logger, "Test", func() error {
+		attempts++
+		return errors.New("transient")
+	})
+	assert.EqualError(t, err, "transient (gave up after 3 attempts)")
+	assert.Equal(t, 3, attempts)
+
+	attempts = 0
+	err = r.retry(context.Background(), logger, "Test", func() error {
+		attempts++
+		return permanent(errors.New("not found"))
+	})
+	assert.EqualError(t, err, "not found")
+	assert.Equal(t, 1, attempts)
+}
+
+func TestUnexpectedStatusError(t *testing.T) {
+	var pe *permanentError
+	assert.True(t, errors.As(unexpectedStatusError(404), &pe))
+	assert.False(t, errors.As(unexpectedStatusError(503), &pe))
+	assert.False(t, errors.As(unexpectedStatusError(429), &pe))
+}
+
+func TestRetryCancelled(t *testing.T) {
+	r := &RetryAware{Retries: 5, RetryBackoff: time.Hour}
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	err := r.retry(ctx, &Logger{Quiet: true}, "Test", func() error {
+		attempts++
+		cancel()
+		return errors.New("transient")
+	})
+	assert.EqualError(t, err, "transient")
+	assert.Equal(t, 1, attempts)
+}
@@ -0,0 +1,189 @@
+package selenoid
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+)
+
+const (
+	ChannelStable = "stable"
+	ChannelBeta   = "beta"
+	ChannelDev    = "dev"
+
+	comma = ","
+
+	selectorLast    = "last"
+	selectorExact   = "exact"
+	selectorChannel = "channel"
+)
+
+// browserSelector tells which image tags of a browser should be configured.
+// All conditions of a selector should match, version constraints from several selectors of the same browser are alternatives.
+type browserSelector struct {
+	constraints []*semver.Constraints
+	exact       []string
+	last        int
+	channel     string
+}
+
+// parseRequestedBrowsers parses semicolon separated browser selectors like chrome:last=4;firefox:>=120,last=2;opera:exact=106.0;chrome:beta
+func parseRequestedBrowsers(requestedBrowsers string) (map[string]*browserSelector, error) {
+	ret := make(map[string]*browserSelector)
+	for _, section := range strings.Split(requestedBrowsers, semicolon) {
+		if strings.TrimSpace(section) == "" {
+			continue
+		}
+		browserName, spec, hasSpec := strings.Cut(section, colon)
+		browserName = strings.TrimSpace(browserName)
+		if browserName == "" {
+			return nil, fmt.Errorf(`invalid browser selector "%s": browser name is not specified`, section)
+		}
+		selector, ok := ret[browserName]
+		if !ok {
+			selector = &browserSelector{}
+			ret[browserName] = selector
+		}
+		if hasSpec {
+			if err := selector.parse(spec); err != nil {
+				return nil, fmt.Errorf(`invalid browser selector "%s": %v`, strings.TrimSpace(section), err)
+			}
+		}
+	}
+	return ret, nil
+}
+
+func (s *browserSelector) parse(spec string) error {
+	var constraints []string
+	for _, item := range strings.Split(spec, comma) {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			return errors.New("empty condition")
+		}
+		key, value, _ := strings.Cut(item, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		switch {
+		case key == selectorLast:
+			last, err := strconv.Atoi(value)
+			if err != nil || last <= 0 {
+				return fmt.Errorf("last should be a positive number: %s", value)
+			}
+			s.last = last
+		case key == selectorExact:
+			if value == "" {
+				return errors.New("exact version is not specified")
+			}
+			s.exact = append(s.exact, value)
+		case key == selectorChannel:
+			if err := s.setChannel(value); err != nil {
+				return err
+			}
+		case isChannel(item):
+			if err := s.setChannel(item); err != nil {
+				return err
+			}
+		case isWord(key):
+			return fmt.Errorf("unknown condition: %s", item)
+		default:
+			constraints = append(constraints, item)
+		}
+	}
+	if len(constraints) > 0 {
+		constraint := strings.Join(constraints, comma)
+		versionConstraint, err := semver.NewConstraint(constraint)
+		if err != nil {
+			return fmt.Errorf("invalid version constraint %s: %v", constraint, err)
+		}
+		s.constraints = append(s.constraints, versionConstraint)
+	}
+	return nil
+}
+
+func (s *browserSelector) setChannel(channel string) error {
+	if !isChannel(channel) {
+		return fmt.Errorf("unknown channel %s, should be one of %s, %s or %s", channel, ChannelStable, ChannelBeta, ChannelDev)
+	}
+	if s.channel != "" && s.channel != channel {
+		return fmt.Errorf("conflicting channels %s and %s", s.channel, channel)
+	}
+	s.channel = channel
+	return nil
+}
+
+// unsupportedBrowsersError lists requested browsers that can not be configured
+func unsupportedBrowsersError(browserNames []string) error {
+	sort.Strings(browserNames)
+	return fmt.Errorf("unsupported browsers: %s", strings.Join(browserNames, ", "))
+}
+
+func isChannel(s string) bool {
+	return s == ChannelStable || s == ChannelBeta || s == ChannelDev
+}
+
+func isWord(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// matches tells whether tag satisfies selector, non-semantic tags can only be selected with exact condition
+func (s *browserSelector) matches(tag string) (bool, error) {
+	if len(s.exact) > 0 && !contains(s.exact, tag) {
+		return false, nil
+	}
+	if s.channel == "" && len(s.constraints) == 0 {
+		return true, nil
+	}
+	version, err := semver.NewVersion(tag)
+	if err != nil {
+		return false, err
+	}
+	if s.channel != "" {
+		if !tagChannelMatches(version, s.channel) {
+			return false, nil
+		}
+		if s.channel != ChannelStable {
+			// Prerelease versions are compared with constraints as if they were released, so that chrome:beta,>=120 works
+			stripped, _ := version.SetPrerelease("")
+			version = &stripped
+		}
+	}
+	if len(s.constraints) == 0 {
+		return true, nil
+	}
+	for _, vc := range s.constraints {
+		if vc.Check(version) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// tagChannelMatches tells whether version is from channel, e.g. 121.0-beta is from beta channel and 120.0 from stable one
+func tagChannelMatches(version *semver.Version, channel string) bool {
+	if channel == ChannelStable {
+		return version.Prerelease() == ""
+	}
+	return strings.HasPrefix(version.Prerelease(), channel)
+}
+
+// getLast returns how many last matching tags should be configured, 0 means all of them
+func (s *browserSelector) getLast(lastVersions int) int {
+	if s.last > 0 {
+		return s.last
+	}
+	if len(s.constraints) > 0 || len(s.exact) > 0 {
+		return 0
+	}
+	return lastVersions
+}
@@ -0,0 +1,83 @@
+package selenoid
+
+import (
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func TestParseRequestedBrowsers(t *testing.T) {
+	output, err := parseRequestedBrowsers("firefox:>45.0,51.0;opera; android:7.1;firefox:<50.0")
+	assert.NoError(t, err)
+	assert.Len(t, output, 3)
+
+	ff, ok := output["firefox"]
+	assert.True(t, ok)
+	assert.NotNil(t, ff)
+	assert.Len(t, ff.constraints, 2)
+
+	opera, ok := output["opera"]
+	assert.True(t, ok)
+	assert.Empty(t, opera.constraints)
+
+	android, ok := output["android"]
+	assert.True(t, ok)
+	assert.NotNil(t, android)
+	assert.Len(t, android.constraints, 1)
+}
+
+func TestParseBrowserSelectors(t *testing.T) {
+	output, err := parseRequestedBrowsers("chrome:last=4;firefox:>=120, last=2;opera:exact=106.0,exact=105.0;MicrosoftEdge:channel=dev;android:beta;")
+	assert.NoError(t, err)
+	assert.Len(t, output, 5)
+	assert.Equal(t, 4, output["chrome"].last)
+	assert.Equal(t, 2, output["firefox"].last)
+	assert.Len(t, output["firefox"].constraints, 1)
+	assert.Equal(t, []string{"106.0", "105.0"}, output["opera"].exact)
+	assert.Equal(t, ChannelDev, output["MicrosoftEdge"].channel)
+	assert.Equal(t, ChannelBeta, output["android"].channel)
+
+	for _, invalid := range []string{
+		"firefox:>abc",
+		"firefox:",
+		"firefox:>=120,",
+		"chrome:last=0",
+		"chrome:last=many",
+		"opera:exact=",
+		"chrome:channel=canary",
+		"chrome:latest=2",
+		":>=120",
+		"chrome:beta;chrome:stable",
+		"chrome:channel=beta,dev",
+	} {
+		_, err = parseRequestedBrowsers(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestSameChannelRepeated(t *testing.T) {
+	output, err := parseRequestedBrowsers("chrome:beta;chrome:beta,>=120")
+	assert.NoError(t, err)
+	assert.Equal(t, ChannelBeta, output["chrome"].channel)
+}
+
+func TestFilterTags(t *testing.T) {
+	c := &DockerConfigurator{Logger: Logger{Quiet: true}, LastVersions: 2}
+	tags := []string{"122.0-dev", "121.0-beta", "120.0", "119.0", "118.0", "custom"}
+	for selectors, expected := range map[string][]string{
+		"chrome":                     {"122.0-dev", "121.0-beta"},
+		"chrome:last=3":              {"122.0-dev", "121.0-beta", "120.0"},
+		"chrome:>=119.0":             {"120.0", "119.0"},
+		"chrome:>=118.0,last=1":      {"120.0"},
+		"chrome:stable":              {"120.0", "119.0"},
+		"chrome:beta":                {"121.0-beta"},
+		"chrome:dev,>=122.0":         {"122.0-dev"},
+		"chrome:exact=119.0":         {"119.0"},
+		"chrome:exact=custom":        {"custom"},
+		"chrome:<119.0;chrome:120.0": {"120.0", "118.0"},
+	} {
+		requestedBrowsers, err := parseRequestedBrowsers(selectors)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, c.filterTags(tags, requestedBrowsers["chrome"]), selectors)
+	}
+}
@@ -0,0 +1,196 @@
+package selenoid
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fvbommel/sortorder"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	ModeDocker  = "docker"
+	ModePodman  = "podman"
+	ModeDrivers = "drivers"
+
+	ServiceSelenoid   = "selenoid"
+	ServiceSelenoidUI = "selenoid-ui"
+
+	OutputText = "text"
+	OutputJSON = "json"
+	OutputYAML = "yaml"
+
+	defaultInstance = "default"
+)
+
+// ServiceStatus is a machine-readable state of Selenoid or Selenoid UI installation
+type ServiceStatus struct {
+	Service        string `json:"service" yaml:"service"`
+	Instance       string `json:"instance,omitempty" yaml:"instance,omitempty"`
+	Mode           string `json:"mode" yaml:"mode"`
+	Version        string `json:"version,omitempty" yaml:"version,omitempty"`
+	Downloaded     bool   `json:"downloaded" yaml:"downloaded"`
+	ImageRef       string `json:"imageRef,omitempty" yaml:"imageRef,omitempty"`
+	ImageID        string `json:"imageId,omitempty" yaml:"imageId,omitempty"`
+	BinaryPath     string `json:"binaryPath,omitempty" yaml:"binaryPath,omitempty"`
+	ConfigDir      string `json:"configDir,omitempty" yaml:"configDir,omitempty"`
+	Configured     bool   `json:"configured" yaml:"configured"`
+	ConfigPath     string `json:"configPath,omitempty" yaml:"configPath,omitempty"`
+	Running        bool   `json:"running" yaml:"running"`
+	ContainerName  string `json:"containerName,omitempty" yaml:"containerName,omitempty"`
+	ContainerID    string `json:"containerId,omitempty" yaml:"containerId,omitempty"`
+	ContainerState string `json:"containerState,omitempty" yaml:"containerState,omitempty"`
+	PID            int    `json:"pid,omitempty" yaml:"pid,omitempty"`
+	SystemdUnit    string `json:"systemdUnit,omitempty" yaml:"systemdUnit,omitempty"`
+	Port           int    `json:"port,omitempty" yaml:"port,omitempty"`
+
+	Browsers []BrowserStatus `json:"browsers,omitempty" yaml:"browsers,omitempty"`
+}
+
+// BrowserStatus describes a browser configured in browsers.json
+type BrowserStatus struct {
+	Name     string   `json:"name" yaml:"name"`
+	Protocol string   `json:"protocol" yaml:"protocol"`
+	Default  string   `json:"default,omitempty" yaml:"default,omitempty"`
+	Versions []string `json:"versions" yaml:"versions"`
+}
+
+func (s *ServiceStatus) displayName() string {
+	if s.Service == ServiceSelenoidUI {
+		return "Selenoid UI"
+	}
+	return "Selenoid"
+}
+
+// fillConfigStatus marks service as configured and lists browsers when browsers.json exists
+func fillConfigStatus(status *ServiceStatus, configPath string) {
+	if !fileExists(configPath) {
+		return
+	}
+	status.Configured = true
+	status.ConfigPath = configPath
+	if cfg, err := readSelenoidConfig(configPath); err == nil {
+		status.Browsers = browsersStatus(cfg)
+	}
+}
+
+func browsersStatus(cfg SelenoidConfig) []BrowserStatus {
+	var ret []BrowserStatus
+	for browserName, versions := range cfg {
+		bs := BrowserStatus{Name: browserName, Protocol: ProtocolWebDriver, Default: versions.Default}
+		for version, browser := range versions.Versions {
+			bs.Versions = append(bs.Versions, version)
+			if browser == nil {
+				continue
+			}
+			if protocol, ok := browser.Labels[protocolLabel]; ok {
+				bs.Protocol = protocol
+			}
+		}
+		sort.Sort(sort.Reverse(sortorder.Natural(bs.Versions)))
+		ret = append(ret, bs)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Name < ret[j].Name
+	})
+	return ret
+}
+
+func printStatus(logger *Logger, w io.Writer, status *ServiceStatus, output string) error {
+	switch output {
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "    ")
+		return enc.Encode(status)
+	case OutputYAML:
+		enc := yaml.NewEncoder(w)
+		defer enc.Close()
+		return enc.Encode(status)
+	case OutputText, "":
+		printTextStatus(logger, status)
+		return nil
+	}
+	return fmt.Errorf("unsupported output format: %s", output)
+}
+
+func printStatuses(w io.Writer, statuses []*ServiceStatus, output string) error {
+	switch output {
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "    ")
+		return enc.Encode(statuses)
+	case OutputYAML:
+		enc := yaml.NewEncoder(w)
+		defer enc.Close()
+		return enc.Encode(statuses)
+	case OutputText, "":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "INSTANCE\tSERVICE\tMODE\tRUNNING\tPORT\tCONTAINER/PID\tCONFIG DIR")
+		for _, s := range statuses {
+			instance := s.Instance
+			if instance == "" {
+				instance = defaultInstance
+			}
+			id := s.ContainerName
+			if s.Mode == ModeDrivers && s.PID > 0 {
+				id = strconv.Itoa(s.PID)
+			}
+			port := ""
+			if s.Port > 0 {
+				port = strconv.Itoa(s.Port)
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%t\t%s\t%s\t%s\n", instance, s.Service, s.Mode, s.Running, port, id, s.ConfigDir)
+		}
+		return tw.Flush()
+	}
+	return fmt.Errorf("unsupported output format: %s", output)
+}
+
+func printTextStatus(logger *Logger, s *ServiceStatus) {
+	name := s.displayName()
+	if s.Mode != ModeDrivers {
+		if s.Downloaded {
+			logger.Pointf("Using %s image: %s (%s)", name, s.ImageRef, s.ImageID)
+		} else {
+			logger.Pointf("%s image is not present", name)
+		}
+	} else {
+		if s.Downloaded {
+			logger.Pointf("%s binary is %s", name, s.BinaryPath)
+		} else {
+			logger.Pointf("%s binary is not downloaded", name)
+		}
+	}
+	if s.ConfigDir != "" {
+		logger.Pointf("%s configuration directory is %s", name, s.ConfigDir)
+		if s.Configured {
+			logger.Pointf("%s configuration file is %s", name, s.ConfigPath)
+			for _, b := range s.Browsers {
+				logger.Pointf("Browser %s (%s): %s", b.Name, b.Protocol, strings.Join(b.Versions, ", "))
+			}
+		} else {
+			logger.Pointf("%s is not configured", name)
+		}
+	}
+	if s.Mode != ModeDrivers {
+		if s.Running {
+			logger.Pointf("%s container is running: %s (%s)", name, s.ContainerName, s.ContainerID)
+		} else {
+			logger.Pointf("%s container is not running", name)
+		}
+	} else {
+		if s.Running {
+			logger.Pointf("%s is running as process %d", name, s.PID)
+		} else {
+			logger.Pointf("%s is not running", name)
+		}
+		if s.SystemdUnit != "" {
+			logger.Pointf("%s is managed by systemd unit %s", name, s.SystemdUnit)
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package selenoid
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+const (
+	selenoidSupervisorPidFileName   = "selenoid-supervisor.pid"
+	selenoidUISupervisorPidFileName = "selenoid-ui-supervisor.pid"
+)
+
+var (
+	superviseMinBackoff = time.Second
+	superviseMaxBackoff = time.Minute
+
+	// Process running longer than this is considered healthy and restart backoff is reset
+	superviseStableRun = time.Minute
+)
+
+// foregroundProcess describes a binary run attached to cm
+type foregroundProcess struct {
+	Name           string
+	BinaryPath     string
+	Args           []string
+	Env            []string
+	LogFile        string
+	PidFile        string
+	SupervisorFile string
+}
+
+func (d *DriversConfigurator) runForeground(ctx context.Context, fp *foregroundProcess) error {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+	return d.supervise(ctx, fp, signals)
+}
+
+// supervise runs process until a terminating signal is received or context is cancelled restarting it with exponential backoff when supervision is enabled
+func (d *DriversConfigurator) supervise(ctx context.Context, fp *foregroundProcess, signals <-chan os.Signal) error {
+	f, err := os.OpenFile(fp.LogFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %v", err)
+	}
+	defer f.Close()
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to determine cm executable: %v", err)
+	}
+	err = writePidFile(fp.SupervisorFile, os.Getpid(), executable)
+	if err != nil {
+		return fmt.Errorf("failed to save supervisor information: %v", err)
+	}
+	defer removePidFile(fp.SupervisorFile)
+	defer removePidFile(fp.PidFile)
+
+	logEvent := func(format string, v ...interface{}) {
+		msg := fmt.Sprintf(format, v...)
+		_, _ = fmt.Fprintf(f, "%s [CM] %s\n", time.Now().Format(logTimestampLayout), msg)
+		d.Pointf("%s", msg)
+	}
+	backoff := superviseMinBackoff
+	for {
+		cmd := execCommand(fp.BinaryPath, fp.Args...)
+		cmd.Env = fp.Env
+		cmd.Stdout = io.MultiWriter(os.Stdout, f)
+		cmd.Stderr = io.MultiWriter(os.Stderr, f)
+		started := time.Now()
+		err = cmd.Start()
+		if err != nil {
+			return fmt.Errorf("failed to start %s: %v", fp.Name, err)
+		}
+		err = writePidFile(fp.PidFile, cmd.Process.Pid, cmd.Path)
+		if err != nil {
+			_ = cmd.Process.Kill()
+			return fmt.Errorf("failed to save process information: %v", err)
+		}
+		done := make(chan error, 1)
+		go func() {
+			done <- cmd.Wait()
+		}()
+
+	running:
+		for {
+			select {
+			case s := <-signals:
+				_ = cmd.Process.Signal(s)
+				if s == syscall.SIGHUP {
+					logEvent("Forwarded %v to %s", s, fp.Name)
+					continue
+				}
+				logEvent("Received %v, stopping %s", s, fp.Name)
+				<-done
+				return nil
+			case <-ctx.Done():
+				_ = cmd.Process.Signal(syscall.SIGTERM)
+				logEvent("Cancelled (%v), stopping %s", ctx.Err(), fp.Name)
+				<-done
+				return nil
+			case err = <-done:
+				break running
+			}
+		}
+
+		exitStatus := "exit status 0"
+		if err != nil {
+			exitStatus = err.Error()
+		}
+		// Clean exit means that process was stopped on purpose, so it is not restarted
+		if err == nil || !d.Supervise {
+			logEvent("%s exited: %s", fp.Name, exitStatus)
+			if err != nil {
+				return fmt.Errorf("%s exited: %s", fp.Name, exitStatus)
+			}
+			return nil
+		}
+		if time.Since(started) >= superviseStableRun {
+			backoff = superviseMinBackoff
+		}
+		logEvent("%s exited unexpectedly (%s), restarting in %v", fp.Name, exitStatus, backoff)
+		select {
+		case s := <-signals:
+			logEvent("Received %v, not restarting %s", s, fp.Name)
+			return nil
+		case <-ctx.Done():
+			logEvent("Cancelled (%v), not restarting %s", ctx.Err(), fp.Name)
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > superviseMaxBackoff {
+			backoff = superviseMaxBackoff
+		}
+	}
+}
+
+// stopSupervisor asks supervising cm process to exit without restarting the binary being stopped.
+// Supervisor forwards SIGTERM to the binary, so that it can finish gracefully.
+func (d *DriversConfigurator) stopSupervisor(supervisorFileName string) error {
+	supervisorFile := d.getPidFilePath(supervisorFileName)
+	for _, p := range findTrackedProcesses(supervisorFile) {
+		if err := terminateSupervisor(p); err != nil {
+			return fmt.Errorf("failed to stop supervisor: %v", err)
+		}
+	}
+	return removePidFile(supervisorFile)
+}
+
+func terminateSupervisor(p *os.Process) error {
+	if isWindows() {
+		return p.Kill()
+	}
+	return p.Signal(syscall.SIGTERM)
+}
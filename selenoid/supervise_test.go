@@ -0,0 +1,198 @@
+package selenoid
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+// TestSupervisedHelperProcess is run as a supervised binary: it fails or sleeps depending on environment variable
+func TestSupervisedHelperProcess(t *testing.T) {
+	switch os.Getenv("GO_WANT_SUPERVISED_PROCESS") {
+	case "fail":
+		os.Exit(1)
+	case "sleep":
+		time.Sleep(time.Minute)
+		os.Exit(0)
+	}
+}
+
+// supervisedExecCommand runs TestSupervisedHelperProcess, its mode is passed with foregroundProcess environment
+func supervisedExecCommand(command string, args ...string) *exec.Cmd {
+	cs := []string{"-test.run=TestSupervisedHelperProcess", "--", command}
+	return exec.Command(os.Args[0], append(cs, args...)...)
+}
+
+func supervisedProcessEnv(mode string) []string {
+	return []string{"GO_WANT_SUPERVISED_PROCESS=" + mode}
+}
+
+// waitForLog waits until log file contains message at least count times
+func waitForLog(logFile string, message string, count int) {
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		data, _ := os.ReadFile(logFile)
+		if strings.Count(string(data), message) >= count {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSuperviseRestartsProcess(t *testing.T) {
+	execCommand = supervisedExecCommand
+	superviseMinBackoff = 10 * time.Millisecond
+	defer func() {
+		execCommand = exec.Command
+		superviseMinBackoff = time.Second
+	}()
+	withTmpDir(t, "supervise", func(t *testing.T, dir string) {
+		configurator := NewDriversConfigurator(&LifecycleConfig{
+			ConfigDir:  dir,
+			Quiet:      true,
+			Foreground: true,
+			Supervise:  true,
+		})
+		fp := &foregroundProcess{
+			Name:           "Selenoid",
+			BinaryPath:     "selenoid",
+			Env:            supervisedProcessEnv("fail"),
+			LogFile:        filepath.Join(dir, selenoidLogFileName),
+			PidFile:        filepath.Join(dir, selenoidPidFileName),
+			SupervisorFile: filepath.Join(dir, selenoidSupervisorPidFileName),
+		}
+		signals := make(chan os.Signal, 1)
+		go func() {
+			waitForLog(fp.LogFile, "Selenoid exited unexpectedly", 3)
+			signals <- syscall.SIGTERM
+		}()
+		assert.NoError(t, configurator.supervise(context.Background(), fp, signals))
+
+		data, err := os.ReadFile(fp.LogFile)
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, strings.Count(string(data), "Selenoid exited unexpectedly (exit status 1)"), 3)
+		assert.Contains(t, string(data), "Received terminated")
+		assert.False(t, fileExists(fp.PidFile))
+		assert.False(t, fileExists(fp.SupervisorFile))
+	})
+}
+
+func TestSuperviseDoesNotRestartCleanExit(t *testing.T) {
+	execCommand = fakeExecCommand
+	superviseMinBackoff = 10 * time.Millisecond
+	defer func() {
+		execCommand = exec.Command
+		superviseMinBackoff = time.Second
+	}()
+	withTmpDir(t, "supervise", func(t *testing.T, dir string) {
+		configurator := NewDriversConfigurator(&LifecycleConfig{
+			ConfigDir:  dir,
+			Quiet:      true,
+			Foreground: true,
+			Supervise:  true,
+		})
+		fp := &foregroundProcess{
+			Name:           "Selenoid",
+			BinaryPath:     "selenoid",
+			LogFile:        filepath.Join(dir, selenoidLogFileName),
+			PidFile:        filepath.Join(dir, selenoidPidFileName),
+			SupervisorFile: filepath.Join(dir, selenoidSupervisorPidFileName),
+		}
+		assert.NoError(t, configurator.supervise(context.Background(), fp, make(chan os.Signal)))
+		data, err := os.ReadFile(fp.LogFile)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "Selenoid exited: exit status 0")
+		assert.NotContains(t, string(data), "restarting")
+	})
+}
+
+func TestStopSupervisorTerminates(t *testing.T) {
+	if isWindows() {
+		t.Skip("signals are not supported on Windows")
+	}
+	withTmpDir(t, "stop-supervisor", func(t *testing.T, dir string) {
+		configurator := NewDriversConfigurator(&LifecycleConfig{
+			ConfigDir: dir,
+			Quiet:     true,
+		})
+		cmd := supervisedExecCommand("cm")
+		cmd.Env = supervisedProcessEnv("sleep")
+		assert.NoError(t, cmd.Start())
+		defer func() { _ = cmd.Process.Kill() }()
+		assert.NoError(t, writePidFile(configurator.getPidFilePath(selenoidSupervisorPidFileName), cmd.Process.Pid, os.Args[0]))
+
+		assert.NoError(t, configurator.stopSupervisor(selenoidSupervisorPidFileName))
+		_ = cmd.Wait()
+		status, ok := cmd.ProcessState.Sys().(syscall.WaitStatus)
+		assert.True(t, ok)
+		assert.Equal(t, syscall.SIGTERM, status.Signal())
+		assert.False(t, fileExists(configurator.getPidFilePath(selenoidSupervisorPidFileName)))
+	})
+}
+
+func TestForegroundWithoutSupervision(t *testing.T) {
+	execCommand = fakeExecCommand
+	defer func() {
+		execCommand = exec.Command
+	}()
+	withTmpDir(t, "foreground", func(t *testing.T, dir string) {
+		configurator := NewDriversConfigurator(&LifecycleConfig{
+			ConfigDir:  dir,
+			Quiet:      true,
+			Foreground: true,
+		})
+		fp := &foregroundProcess{
+			Name:           "Selenoid UI",
+			BinaryPath:     "selenoid-ui",
+			LogFile:        filepath.Join(dir, selenoidUILogFileName),
+			PidFile:        filepath.Join(dir, selenoidUIPidFileName),
+			SupervisorFile: filepath.Join(dir, selenoidUISupervisorPidFileName),
+		}
+		assert.NoError(t, configurator.supervise(context.Background(), fp, make(chan os.Signal)))
+		data, err := os.ReadFile(fp.LogFile)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "Selenoid UI exited: exit status 0")
+		assert.NotContains(t, string(data), "restarting")
+	})
+}
+
+func TestSuperviseCancelled(t *testing.T) {
+	execCommand = supervisedExecCommand
+	superviseMinBackoff = 10 * time.Millisecond
+	defer func() {
+		execCommand = exec.Command
+		superviseMinBackoff = time.Second
+	}()
+	withTmpDir(t, "supervise", func(t *testing.T, dir string) {
+		configurator := NewDriversConfigurator(&LifecycleConfig{
+			ConfigDir:  dir,
+			Quiet:      true,
+			Foreground: true,
+			Supervise:  true,
+		})
+		fp := &foregroundProcess{
+			Name:           "Selenoid",
+			BinaryPath:     "selenoid",
+			Env:            supervisedProcessEnv("sleep"),
+			LogFile:        filepath.Join(dir, selenoidLogFileName),
+			PidFile:        filepath.Join(dir, selenoidPidFileName),
+			SupervisorFile: filepath.Join(dir, selenoidSupervisorPidFileName),
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+		assert.NoError(t, configurator.supervise(ctx, fp, make(chan os.Signal)))
+
+		data, err := os.ReadFile(fp.LogFile)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "Cancelled (context deadline exceeded)")
+		assert.False(t, fileExists(fp.PidFile))
+		assert.False(t, fileExists(fp.SupervisorFile))
+	})
+}
@@ -0,0 +1,203 @@
+package selenoid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+const systemdUnitSuffix = ".service"
+
+var (
+	systemdSystemUnitDir = "/etc/systemd/system"
+	systemctl            = runSystemctl
+)
+
+// systemdUnit is a user or system service unit installed for Selenoid or Selenoid UI in drivers mode
+type systemdUnit struct {
+	Name   string
+	Path   string
+	System bool
+}
+
+func runSystemctl(system bool, args ...string) (string, error) {
+	if !system {
+		args = append([]string{"--user"}, args...)
+	}
+	output, err := exec.Command("systemctl", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("systemctl %s failed: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(output)))
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func getSystemdUnitDir(system bool) string {
+	if system {
+		return systemdSystemUnitDir
+	}
+	if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" {
+		return filepath.Join(configHome, "systemd", "user")
+	}
+	return joinPaths(getHomeDir(), []string{".config", "systemd", "user"})
+}
+
+func newSystemdUnit(name string, system bool) *systemdUnit {
+	name += systemdUnitSuffix
+	return &systemdUnit{Name: name, Path: filepath.Join(getSystemdUnitDir(system), name), System: system}
+}
+
+// findSystemdUnit returns installed unit with given name, user units take precedence over system ones
+func findSystemdUnit(name string) *systemdUnit {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+	for _, system := range []bool{false, true} {
+		unit := newSystemdUnit(name, system)
+		if fileExists(unit.Path) {
+			return unit
+		}
+	}
+	return nil
+}
+
+func (u *systemdUnit) start() error {
+	_, err := systemctl(u.System, "start", u.Name)
+	return err
+}
+
+func (u *systemdUnit) stop() error {
+	_, err := systemctl(u.System, "stop", u.Name)
+	return err
+}
+
+func (u *systemdUnit) findProcesses() []*os.Process {
+	output, err := systemctl(u.System, "show", "--property", "MainPID", "--value", u.Name)
+	if err != nil {
+		return nil
+	}
+	pid, err := strconv.Atoi(output)
+	if err != nil || pid <= 0 {
+		return nil
+	}
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return nil
+	}
+	return []*os.Process{p}
+}
+
+func (u *systemdUnit) install(description string, command []string, env []string, logFile string) error {
+	err := os.MkdirAll(filepath.Dir(u.Path), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("failed to create systemd unit directory: %v", err)
+	}
+	content, err := u.render(description, command, env, logFile)
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(u.Path, []byte(content), 0644)
+	if err != nil {
+		return fmt.Errorf("failed to save systemd unit: %v", err)
+	}
+	for _, args := range [][]string{{"daemon-reload"}, {"enable", u.Name}, {"restart", u.Name}} {
+		if _, err := systemctl(u.System, args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (u *systemdUnit) render(description string, command []string, env []string, logFile string) (string, error) {
+	var execStart []string
+	for _, arg := range command {
+		execStart = append(execStart, quoteUnitValue(strings.ReplaceAll(arg, "$", "$$")))
+	}
+	var b strings.Builder
+	_, _ = fmt.Fprintf(&b, "[Unit]\nDescription=%s\nWants=network-online.target\nAfter=network-online.target\n\n", description)
+	_, _ = fmt.Fprintf(&b, "[Service]\nExecStart=%s\n", strings.Join(execStart, " "))
+	for _, e := range env {
+		_, _ = fmt.Fprintf(&b, "Environment=%s\n", quoteUnitValue(e))
+	}
+	logFile = strings.ReplaceAll(logFile, "%", "%%")
+	_, _ = fmt.Fprintf(&b, "StandardOutput=append:%s\nStandardError=append:%s\n", logFile, logFile)
+	_, _ = fmt.Fprintf(&b, "Restart=always\nRestartSec=5\n")
+	wantedBy := "default.target"
+	if u.System {
+		// System units run as the user who installed them to keep access to downloaded binaries and configuration
+		usr, err := user.Current()
+		if err != nil {
+			return "", fmt.Errorf("failed to determine current user: %v", err)
+		}
+		_, _ = fmt.Fprintf(&b, "User=%s\n", usr.Username)
+		wantedBy = "multi-user.target"
+	}
+	_, _ = fmt.Fprintf(&b, "\n[Install]\nWantedBy=%s\n", wantedBy)
+	return b.String(), nil
+}
+
+func quoteUnitValue(value string) string {
+	value = strings.ReplaceAll(value, "%", "%%")
+	if value == "" || strings.ContainsAny(value, " \t\"'\\") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// installService replaces running unmanaged processes by a systemd unit started with the same arguments
+func (d *DriversConfigurator) installService(description string, name string, binaryPath string, command func() ([]string, []string), logFileName string, system bool, findProcesses func() []*os.Process) error {
+	if runtime.GOOS != "linux" {
+		return errors.New("systemd services are only supported on Linux")
+	}
+	configDir, err := filepath.Abs(d.ConfigDir)
+	if err != nil {
+		return fmt.Errorf("failed to determine configuration directory: %v", err)
+	}
+	d.ConfigDir = configDir
+	binaryPath, err = filepath.Abs(binaryPath)
+	if err != nil {
+		return fmt.Errorf("failed to determine binary path: %v", err)
+	}
+	if !fileExists(binaryPath) {
+		return fmt.Errorf("%s binary %s is not downloaded", description, binaryPath)
+	}
+	if findSystemdUnit(name) == nil {
+		err = d.killAllProcesses(findProcesses())
+		if err != nil {
+			return fmt.Errorf("failed to stop running %s: %v", description, err)
+		}
+	}
+	args, env := command()
+	unit := newSystemdUnit(name, system)
+	err = unit.install(description, append([]string{binaryPath}, args...), env, d.getLogFilePath(logFileName))
+	if err != nil {
+		return err
+	}
+	d.Pointf("Installed systemd unit %s", unit.Path)
+	if !system {
+		d.Pointf("To keep user services running after logout execute: loginctl enable-linger")
+	}
+	return nil
+}
+
+func (d *DriversConfigurator) InstallService(_ context.Context, system bool) error {
+	return d.installService("Selenoid", d.instanceName(selenoidRepo), d.getSelenoidBinaryPath(), d.getSelenoidCommand, selenoidLogFileName, system, d.findSelenoidProcesses)
+}
+
+func (d *DriversConfigurator) InstallUIService(_ context.Context, system bool) error {
+	return d.installService("Selenoid UI", d.instanceName(selenoidUIRepo), d.getSelenoidUIBinaryPath(), d.getSelenoidUICommand, selenoidUILogFileName, system, d.findSelenoidUIProcesses)
+}
+
+func (d *DriversConfigurator) findSelenoidUnit() *systemdUnit {
+	return findSystemdUnit(d.instanceName(selenoidRepo))
+}
+
+func (d *DriversConfigurator) findSelenoidUIUnit() *systemdUnit {
+	return findSystemdUnit(d.instanceName(selenoidUIRepo))
+}
@@ -0,0 +1,84 @@
+package selenoid
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	assert "github.com/stretchr/testify/require"
+)
+
+func withFakeSystemctl(t *testing.T, fn func(*testing.T, *[]string)) {
+	var calls []string
+	systemctl = func(system bool, args ...string) (string, error) {
+		calls = append(calls, strings.Join(args, " "))
+		if len(args) > 0 && args[0] == "show" {
+			return "0", nil
+		}
+		return "", nil
+	}
+	defer func() {
+		systemctl = runSystemctl
+	}()
+	fn(t, &calls)
+}
+
+func TestRenderSystemdUnit(t *testing.T) {
+	unit := &systemdUnit{Name: "selenoid.service", Path: "/tmp/selenoid.service"}
+	content, err := unit.render(
+		"Selenoid",
+		[]string{"/opt/selenoid", "-listen", ":4444", "-conf", "/path/with spaces/browsers.json", "-capture-driver-logs", "$HOME"},
+		[]string{"KEY=some value", "OTHER=100%"},
+		"/var/log/selenoid.log",
+	)
+	assert.NoError(t, err)
+	assert.Contains(t, content, `ExecStart=/opt/selenoid -listen :4444 -conf "/path/with spaces/browsers.json" -capture-driver-logs $$HOME`+"\n")
+	assert.Contains(t, content, `Environment="KEY=some value"`+"\n")
+	assert.Contains(t, content, "Environment=OTHER=100%%\n")
+	assert.Contains(t, content, "StandardOutput=append:/var/log/selenoid.log\n")
+	assert.Contains(t, content, "Restart=always\n")
+	assert.Contains(t, content, "WantedBy=default.target\n")
+	assert.NotContains(t, content, "User=")
+}
+
+func TestInstallService(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("systemd is only available on Linux")
+	}
+	withTmpDir(t, "install-service", func(t *testing.T, dir string) {
+		withFakeSystemctl(t, func(t *testing.T, calls *[]string) {
+			t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "xdg"))
+			configDir := filepath.Join(dir, "selenoid")
+			assert.NoError(t, os.MkdirAll(configDir, os.ModePerm))
+			configurator := NewDriversConfigurator(&LifecycleConfig{
+				ConfigDir: configDir,
+				Version:   Latest,
+				Port:      4445,
+				Instance:  "team-a",
+			})
+			assert.Error(t, configurator.InstallService(context.Background(), false))
+
+			assert.NoError(t, os.WriteFile(configurator.getSelenoidBinaryPath(), []byte("binary"), 0755))
+			assert.NoError(t, configurator.InstallService(context.Background(), false))
+			unitPath := filepath.Join(dir, "xdg", "systemd", "user", "team-a_selenoid.service")
+			data, err := os.ReadFile(unitPath)
+			assert.NoError(t, err)
+			assert.Contains(t, string(data), "-listen :4445")
+			assert.Contains(t, string(data), filepath.Join(configDir, selenoidLogFileName))
+			assert.Equal(t, []string{"daemon-reload", "enable team-a_selenoid.service", "restart team-a_selenoid.service"}, *calls)
+
+			*calls = nil
+			assert.False(t, configurator.IsRunning())
+			status := configurator.Status()
+			assert.Equal(t, "team-a_selenoid.service", status.SystemdUnit)
+			assert.NoError(t, configurator.Start(context.Background()))
+			assert.NoError(t, configurator.Stop(context.Background()))
+			assert.Contains(t, *calls, "start team-a_selenoid.service")
+			assert.Contains(t, *calls, "stop team-a_selenoid.service")
+			assert.Nil(t, configurator.findSelenoidUIUnit())
+		})
+	})
+}
@@ -0,0 +1,245 @@
+package selenoid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/aerokube/selenoid/config"
+	"github.com/docker/docker/api/types/image"
+	"github.com/docker/go-units"
+	"github.com/fvbommel/sortorder"
+	"gopkg.in/yaml.v3"
+)
+
+// ValidationReport lists problems of every browsers.json entry
+type ValidationReport struct {
+	ConfigPath string            `json:"configPath" yaml:"configPath"`
+	Valid      bool              `json:"valid" yaml:"valid"`
+	Entries    []EntryValidation `json:"entries" yaml:"entries"`
+}
+
+// EntryValidation lists problems of a browser (when Version is empty) or of its version
+type EntryValidation struct {
+	Browser string   `json:"browser" yaml:"browser"`
+	Version string   `json:"version,omitempty" yaml:"version,omitempty"`
+	Errors  []string `json:"errors,omitempty" yaml:"errors,omitempty"`
+}
+
+// browserChecker returns problems of browsers.json version specific to Docker or drivers mode
+type browserChecker func(ctx context.Context, browser *config.Browser) []string
+
+// validateConfig checks browsers.json entries, every version is also checked with checkBrowser
+func validateConfig(ctx context.Context, configPath string, cfg SelenoidConfig, checkBrowser browserChecker) *ValidationReport {
+	report := &ValidationReport{ConfigPath: configPath, Valid: true, Entries: []EntryValidation{}}
+	add := func(entry EntryValidation) {
+		if len(entry.Errors) > 0 {
+			report.Valid = false
+		}
+		report.Entries = append(report.Entries, entry)
+	}
+	var browserNames []string
+	for browserName := range cfg {
+		browserNames = append(browserNames, browserName)
+	}
+	sort.Strings(browserNames)
+	for _, browserName := range browserNames {
+		versions := cfg[browserName]
+		entry := EntryValidation{Browser: browserName}
+		if len(versions.Versions) == 0 {
+			entry.Errors = append(entry.Errors, "no versions configured")
+		}
+		if versions.Default == "" {
+			entry.Errors = append(entry.Errors, "default version is not specified")
+		} else if _, ok := versions.Versions[versions.Default]; !ok {
+			entry.Errors = append(entry.Errors, fmt.Sprintf("default version %s is missing", versions.Default))
+		}
+		add(entry)
+
+		var versionNames []string
+		for version := range versions.Versions {
+			versionNames = append(versionNames, version)
+		}
+		sort.Sort(sort.Reverse(sortorder.Natural(versionNames)))
+		for _, version := range versionNames {
+			browser := versions.Versions[version]
+			entry := EntryValidation{Browser: browserName, Version: version}
+			if browser == nil {
+				entry.Errors = append(entry.Errors, "version settings are empty")
+				add(entry)
+				continue
+			}
+			entry.Errors = append(entry.Errors, validateBrowser(browser)...)
+			entry.Errors = append(entry.Errors, checkBrowser(ctx, browser)...)
+			add(entry)
+		}
+	}
+	return report
+}
+
+// validateBrowser checks settings common for Docker and drivers mode
+func validateBrowser(browser *config.Browser) []string {
+	var ret []string
+	if browser.Port != "" {
+		if port, err := strconv.Atoi(browser.Port); err != nil || port < 1 || port > 65535 {
+			ret = append(ret, fmt.Sprintf("invalid port %s", browser.Port))
+		}
+	}
+	if browser.Path != "" && !strings.HasPrefix(browser.Path, "/") {
+		ret = append(ret, fmt.Sprintf("path %s should start with /", browser.Path))
+	}
+	var mounts []string
+	for mount := range browser.Tmpfs {
+		mounts = append(mounts, mount)
+	}
+	sort.Strings(mounts)
+	for _, mount := range mounts {
+		if err := validateTmpfs(mount, browser.Tmpfs[mount]); err != nil {
+			ret = append(ret, err.Error())
+		}
+	}
+	if browser.ShmSize < 0 {
+		ret = append(ret, fmt.Sprintf("invalid shmSize %d", browser.ShmSize))
+	}
+	return ret
+}
+
+// validateTmpfs checks tmpfs mount options like size=512m,mode=1777
+func validateTmpfs(mount string, options string) error {
+	if !strings.HasPrefix(mount, "/") {
+		return fmt.Errorf("tmpfs mount point %s should be an absolute path", mount)
+	}
+	for _, option := range strings.Split(options, comma) {
+		key, value, _ := strings.Cut(option, "=")
+		if key == "size" {
+			if _, err := units.RAMInBytes(value); err != nil {
+				return fmt.Errorf("invalid tmpfs size for %s: %s", mount, value)
+			}
+		}
+	}
+	return nil
+}
+
+// Validate checks browsers.json images are present locally or can be pulled from configured registries
+func (c *DockerConfigurator) Validate(ctx context.Context, configPath string) (*ValidationReport, error) {
+	cfg, err := readSelenoidConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	images, err := c.docker.ImageList(ctx, image.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %v", err)
+	}
+	present := localImageRefs(images)
+	return validateConfig(ctx, configPath, cfg, func(ctx context.Context, browser *config.Browser) []string {
+		var ret []string
+		if browser.Port == "" {
+			ret = append(ret, "port is not specified")
+		}
+		ref, ok := browser.Image.(string)
+		if !ok || ref == "" {
+			return append(ret, fmt.Sprintf("image should be a Docker image reference: %v", browser.Image))
+		}
+		if present[ref] {
+			return ret
+		}
+		if _, err := c.fetchImageSize(ctx, ref); err != nil {
+			ret = append(ret, fmt.Sprintf("image %s is not present locally and can not be pulled: %v", ref, err))
+		}
+		return ret
+	}), nil
+}
+
+// Validate checks browsers.json commands point to existing executable files
+func (d *DriversConfigurator) Validate(ctx context.Context, configPath string) (*ValidationReport, error) {
+	cfg, err := readSelenoidConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return validateConfig(ctx, configPath, cfg, func(_ context.Context, browser *config.Browser) []string {
+		command, ok := commandFromImage(browser.Image)
+		if !ok {
+			return []string{fmt.Sprintf("image should be a command array: %v", browser.Image)}
+		}
+		if err := checkExecutable(command[0]); err != nil {
+			return []string{err.Error()}
+		}
+		return nil
+	}), nil
+}
+
+// commandFromImage returns driver command from browsers.json image field parsed as JSON
+func commandFromImage(img interface{}) ([]string, bool) {
+	var ret []string
+	switch v := img.(type) {
+	case []string:
+		ret = v
+	case []interface{}:
+		for _, piece := range v {
+			s, ok := piece.(string)
+			if !ok {
+				return nil, false
+			}
+			ret = append(ret, s)
+		}
+	}
+	if len(ret) == 0 || ret[0] == "" {
+		return nil, false
+	}
+	return ret, true
+}
+
+func checkExecutable(command string) error {
+	path := command
+	if !strings.ContainsRune(command, filepath.Separator) {
+		p, err := exec.LookPath(command)
+		if err != nil {
+			return fmt.Errorf("command %s is not found: %v", command, err)
+		}
+		path = p
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("command %s does not exist", path)
+	}
+	if fi.IsDir() || fi.Mode()&0111 == 0 {
+		return fmt.Errorf("command %s is not executable", path)
+	}
+	return nil
+}
+
+func printValidationReport(logger *Logger, w io.Writer, report *ValidationReport, output string) error {
+	switch output {
+	case OutputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "    ")
+		return enc.Encode(report)
+	case OutputYAML:
+		enc := yaml.NewEncoder(w)
+		defer enc.Close()
+		return enc.Encode(report)
+	case OutputText, "":
+		for _, entry := range report.Entries {
+			name := entry.Browser
+			if entry.Version != "" {
+				name = fmt.Sprintf("%s %s", entry.Browser, entry.Version)
+			}
+			if len(entry.Errors) == 0 {
+				logger.Pointf("%s: OK", name)
+				continue
+			}
+			for _, e := range entry.Errors {
+				logger.Errorf("%s: %s", name, e)
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("unsupported output format: %s", output)
+}
@@ -0,0 +1,163 @@
+package selenoid
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aerokube/selenoid/config"
+	assert "github.com/stretchr/testify/require"
+)
+
+func writeTestConfig(t *testing.T, path string, cfg SelenoidConfig) {
+	data, err := json.Marshal(cfg)
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(path, data, 0644))
+}
+
+func TestValidateConfig(t *testing.T) {
+	cfg := SelenoidConfig{
+		"firefox": {Default: "46.0", Versions: map[string]*config.Browser{
+			"45.0": {Image: "selenoid/firefox:45.0", Port: "port", Path: "wd/hub", Tmpfs: map[string]string{"/tmp": "size=lots"}, ShmSize: -1},
+		}},
+		"opera": {Default: "44.0", Versions: map[string]*config.Browser{
+			"44.0": {Image: "selenoid/opera:44.0", Port: "4444", Path: "/", Tmpfs: map[string]string{"/tmp": "size=512m,mode=1777"}},
+		}},
+	}
+	var checked []string
+	report := validateConfig(context.Background(), "browsers.json", cfg, func(_ context.Context, browser *config.Browser) []string {
+		checked = append(checked, browser.Image.(string))
+		return nil
+	})
+	assert.False(t, report.Valid)
+	assert.Equal(t, []EntryValidation{
+		{Browser: "firefox", Errors: []string{"default version 46.0 is missing"}},
+		{Browser: "firefox", Version: "45.0", Errors: []string{"invalid port port", "path wd/hub should start with /", "invalid tmpfs size for /tmp: lots", "invalid shmSize -1"}},
+		{Browser: "opera"},
+		{Browser: "opera", Version: "44.0"},
+	}, report.Entries)
+	assert.Equal(t, []string{"selenoid/firefox:45.0", "selenoid/opera:44.0"}, checked)
+}
+
+func TestValidateDocker(t *testing.T) {
+	withTmpDir(t, "test-validate", func(t *testing.T, dir string) {
+		c, err := NewDockerConfigurator(&LifecycleConfig{
+			ConfigDir:   dir,
+			RegistryUrl: mockDockerServer.URL,
+			Quiet:       true,
+		})
+		assert.NoError(t, err)
+		defer c.Close()
+		configPath := getSelenoidConfigPath(dir)
+		missingImage := c.browsersRegistry.imageRef("selenoid/opera:1.0")
+		writeTestConfig(t, configPath, SelenoidConfig{
+			"opera": {Default: "44.0", Versions: map[string]*config.Browser{
+				"44.0": {Image: c.browsersRegistry.imageRef("selenoid/opera:44.0"), Port: "4444", Path: "/"},
+				"1.0":  {Image: missingImage, Port: "4444", Path: "/"},
+			}},
+			"selenoid": {Default: Latest, Versions: map[string]*config.Browser{
+				Latest: {Image: "aerokube/selenoid:latest", Path: "/"},
+			}},
+		})
+		report, err := c.Validate(context.Background(), configPath)
+		assert.NoError(t, err)
+		assert.False(t, report.Valid)
+		assert.Len(t, report.Entries, 5)
+		assert.Empty(t, report.Entries[1].Errors)
+		assert.Len(t, report.Entries[2].Errors, 1)
+		assert.Contains(t, report.Entries[2].Errors[0], missingImage)
+		assert.Equal(t, []string{"port is not specified"}, report.Entries[4].Errors)
+
+		_, err = c.Validate(context.Background(), filepath.Join(dir, "missing.json"))
+		assert.Error(t, err)
+	})
+}
+
+func TestValidateDockerOtherRegistry(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/v2/team/chrome/manifests/120.0", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Content-Type", "application/vnd.docker.distribution.manifest.v2+json")
+		_, _ = fmt.Fprintln(w, `{"schemaVersion": 2, "mediaType": "application/vnd.docker.distribution.manifest.v2+json", "config": {"size": 1000}, "layers": [{"size": 2000}]}`)
+	})
+	srv := httptest.NewTLSServer(mux)
+	defer srv.Close()
+	withTmpDir(t, "test-validate", func(t *testing.T, dir string) {
+		c, err := NewDockerConfigurator(&LifecycleConfig{
+			ConfigDir:        dir,
+			RegistryUrl:      mockDockerServer.URL,
+			InsecureRegistry: true,
+			Quiet:            true,
+		})
+		assert.NoError(t, err)
+		defer c.Close()
+		image := hostPort(srv.URL) + "/team/chrome:120.0"
+		configPath := getSelenoidConfigPath(dir)
+		writeTestConfig(t, configPath, SelenoidConfig{
+			"chrome": {Default: "120.0", Versions: map[string]*config.Browser{
+				"120.0": {Image: image, Port: "4444", Path: "/"},
+			}},
+		})
+		report, err := c.Validate(context.Background(), configPath)
+		assert.NoError(t, err)
+		assert.True(t, report.Valid, report.Entries)
+
+		size, err := c.fetchImageSize(context.Background(), image)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3000), size)
+	})
+}
+
+func TestGetPulledImageRegistry(t *testing.T) {
+	c := &DockerConfigurator{RegistryUrl: "https://mirror.example.com"}
+	assert.NoError(t, c.initRegistries())
+	for ref, repository := range map[string]string{
+		"selenoid/chrome:120.0":                 "selenoid/chrome:120.0",
+		"docker.io/selenoid/chrome:120.0":       "selenoid/chrome:120.0",
+		"index.docker.io/selenoid/chrome:120.0": "selenoid/chrome:120.0",
+		"docker.io/ubuntu:22.04":                "library/ubuntu:22.04",
+	} {
+		r, repo, err := c.getPulledImageRegistry(ref)
+		assert.NoError(t, err)
+		assert.Equal(t, DefaultRegistryUrl, r.url, ref)
+		assert.Equal(t, repository, repo, ref)
+	}
+	r, repo, err := c.getPulledImageRegistry("mirror.example.com/selenoid/chrome:120.0")
+	assert.NoError(t, err)
+	assert.Same(t, c.selenoidRegistry, r)
+	assert.Equal(t, "selenoid/chrome:120.0", repo)
+}
+
+func TestValidateDrivers(t *testing.T) {
+	withTmpDir(t, "test-validate", func(t *testing.T, dir string) {
+		driver := filepath.Join(dir, "chromedriver")
+		assert.NoError(t, os.WriteFile(driver, []byte("#!/bin/sh\n"), 0755))
+		notExecutable := filepath.Join(dir, "geckodriver")
+		assert.NoError(t, os.WriteFile(notExecutable, []byte("#!/bin/sh\n"), 0644))
+		configPath := getSelenoidConfigPath(dir)
+		writeTestConfig(t, configPath, SelenoidConfig{
+			"chrome":  {Default: Latest, Versions: map[string]*config.Browser{Latest: {Image: []string{driver, "--port={port}"}, Path: "/"}}},
+			"firefox": {Default: Latest, Versions: map[string]*config.Browser{Latest: {Image: []string{notExecutable}, Path: "/"}}},
+			"opera":   {Default: Latest, Versions: map[string]*config.Browser{Latest: {Image: "selenoid/opera:44.0", Path: "/"}}},
+		})
+		d := NewDriversConfigurator(&LifecycleConfig{ConfigDir: dir, Quiet: true})
+		report, err := d.Validate(context.Background(), configPath)
+		assert.NoError(t, err)
+		assert.False(t, report.Valid)
+		assert.Equal(t, []EntryValidation{
+			{Browser: "chrome"},
+			{Browser: "chrome", Version: Latest},
+			{Browser: "firefox"},
+			{Browser: "firefox", Version: Latest, Errors: []string{"command " + notExecutable + " is not executable"}},
+			{Browser: "opera"},
+			{Browser: "opera", Version: Latest, Errors: []string{"image should be a command array: selenoid/opera:44.0"}},
+		}, report.Entries)
+	})
+}